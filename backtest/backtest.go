@@ -0,0 +1,235 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/H3Cki/Plotor/clients/paper"
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+)
+
+// Config describes the plot order that is replayed
+type Config struct {
+	Plot     geometry.Plot
+	Interval time.Duration
+	// Side is either paper.SIDE_BUY or paper.SIDE_SELL
+	Side     string
+	Quantity float64
+	// Fee is a fraction of the traded value charged for the fill, e.g. 0.001
+	Fee float64
+	// Start and End limit the replayed candles, zero values are unbounded
+	Start, End time.Time
+	// OutOfRange decides what happens to the order when the plot goes out of range, defaults to plotor.OutOfRangeLeave
+	OutOfRange plotor.OutOfRangePolicy
+}
+
+// Fill describes the moment the simulated limit order got filled
+type Fill struct {
+	Time  time.Time
+	Price float64
+	// PlotPrice is the price of the plot at the time of the fill
+	PlotPrice float64
+	// Slippage is the difference between the fill price and the plot price, positive values are unfavourable
+	Slippage float64
+	Fee      float64
+}
+
+// Report summarizes a backtest, Status is RUNNING when the order was still open at the end of the data
+// and STOPPED when the plot went out of range and the order was cancelled or left unfilled at its last price
+type Report struct {
+	Status plotor.Status
+	Err    error
+	Start  time.Time
+	End    time.Time
+	// Ticks is the number of times the order was placed or re-priced
+	Ticks      int
+	Fill       *Fill
+	TimeToFill time.Duration
+	// LastPrice is the close price of the last replayed candle, it's used to value the position
+	LastPrice float64
+	// PnL is the value of the filled position at LastPrice minus its cost and fee
+	PnL float64
+}
+
+// Run replays the candles and simulates a plot order: the order is placed at the start and re-priced at the start of every interval,
+// exactly like a running plotor.PlotOrder. The order is filled when a candle crosses the resting price, or at the open price if the candle gapped through it.
+// When the plot goes out of range the out of range policy is applied, a market order is filled at the open price of the candle.
+func Run(candles []paper.Candle, cfg Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	candles = paper.NewFeed(between(candles, cfg.Start, cfg.End)).Candles
+	if len(candles) == 0 {
+		return nil, errors.New("no candles to replay")
+	}
+
+	start := cfg.Start
+	if start.IsZero() {
+		start = candles[0].Time
+	}
+
+	r := &Report{Status: plotor.StatusRunning, Start: start, End: candles[len(candles)-1].Time, LastPrice: candles[len(candles)-1].Close}
+
+	// the order can not be placed if the plot has no price at the start
+	price, err := cfg.Plot.At(start)
+	if err != nil {
+		r.fail(start, err)
+		return r, nil
+	}
+
+	r.Ticks++
+
+	// ticking is false once the plot order stopped and left the order at its last price
+	ticking := true
+	next := plotor.NextIntervalStart(start, cfg.Interval)
+
+	for _, c := range candles {
+		for ticking && !c.Time.Before(next) {
+			var done bool
+			if price, ticking, done = r.tick(cfg, next, c, price); done {
+				return r, nil
+			}
+
+			next = plotor.NextIntervalStart(next, cfg.Interval)
+		}
+
+		fillPrice, filled := cross(c, cfg.Side, price)
+		if !filled {
+			continue
+		}
+
+		plotPrice, err := cfg.Plot.At(c.Time)
+		if err != nil {
+			// the plot ended within the interval, the resting price is the last known plot price
+			plotPrice = price
+		}
+
+		r.fill(cfg, Fill{Time: c.Time, Price: fillPrice, PlotPrice: plotPrice})
+
+		return r, nil
+	}
+
+	return r, nil
+}
+
+func (cfg Config) validate() error {
+	if cfg.Plot == nil {
+		return errors.New("plot is required")
+	}
+
+	if cfg.Interval < time.Second {
+		return fmt.Errorf("interval must be at least 1s, got %s", cfg.Interval)
+	}
+
+	if cfg.Side != paper.SIDE_BUY && cfg.Side != paper.SIDE_SELL {
+		return fmt.Errorf("unsupported side: %s", cfg.Side)
+	}
+
+	if cfg.Quantity <= 0 {
+		return fmt.Errorf("quantity must be positive, got %f", cfg.Quantity)
+	}
+
+	if cfg.OutOfRange != "" {
+		if err := cfg.OutOfRange.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tick re-prices the order resting at given price at time t, c is the candle the tick falls into.
+// It returns the new resting price, false if the order stopped being re-priced and true if the replay is done.
+func (r *Report) tick(cfg Config, t time.Time, c paper.Candle, price float64) (float64, bool, bool) {
+	newPrice, err := cfg.Plot.At(t)
+	if err == nil {
+		r.Ticks++
+		return newPrice, true, false
+	}
+
+	if !errors.Is(err, geometry.ErrOutOfRange) {
+		r.fail(t, err)
+		return price, false, true
+	}
+
+	switch cfg.OutOfRange {
+	case plotor.OutOfRangeWait:
+		// the order rests at the last price until the plot is back in range
+		return price, true, false
+	case plotor.OutOfRangeMarket:
+		r.fill(cfg, Fill{Time: c.Time, Price: c.Open, PlotPrice: price})
+		return price, false, true
+	}
+
+	r.Status = plotor.StatusStopped
+	r.Err = fmt.Errorf("plot out of range at %s: %w", t, err)
+
+	// the order is left at the last price and can still be filled
+	return price, false, cfg.OutOfRange == plotor.OutOfRangeCancel
+}
+
+func (r *Report) fail(t time.Time, err error) {
+	r.Status = plotor.StatusFailed
+	r.Err = fmt.Errorf("error getting plot price at %s: %w", t, err)
+}
+
+func (r *Report) fill(cfg Config, f Fill) {
+	f.Fee = f.Price * cfg.Quantity * cfg.Fee
+	f.Slippage = f.Price - f.PlotPrice
+
+	if cfg.Side == paper.SIDE_SELL {
+		f.Slippage = -f.Slippage
+	}
+
+	r.Status = plotor.StatusFilled
+	r.Fill = &f
+	r.TimeToFill = f.Time.Sub(r.Start)
+
+	switch cfg.Side {
+	case paper.SIDE_BUY:
+		r.PnL = (r.LastPrice-f.Price)*cfg.Quantity - f.Fee
+	case paper.SIDE_SELL:
+		r.PnL = (f.Price-r.LastPrice)*cfg.Quantity - f.Fee
+	}
+}
+
+// cross returns the price a limit order resting at given price would be filled at during the candle
+func cross(c paper.Candle, side string, price float64) (float64, bool) {
+	switch side {
+	case paper.SIDE_BUY:
+		if c.Open <= price {
+			return c.Open, true
+		}
+
+		return price, c.Low <= price
+	case paper.SIDE_SELL:
+		if c.Open >= price {
+			return c.Open, true
+		}
+
+		return price, c.High >= price
+	}
+
+	return 0, false
+}
+
+func between(candles []paper.Candle, start, end time.Time) []paper.Candle {
+	res := []paper.Candle{}
+
+	for _, c := range candles {
+		if !start.IsZero() && c.Time.Before(start) {
+			continue
+		}
+
+		if !end.IsZero() && c.Time.After(end) {
+			continue
+		}
+
+		res = append(res, c)
+	}
+
+	return res
+}
@@ -0,0 +1,148 @@
+package backtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/backtest"
+	"github.com/H3Cki/Plotor/clients/paper"
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func minute(m int64) time.Time {
+	return time.Unix(m*60, 0).UTC()
+}
+
+func candle(m int64, open, high, low, close float64) paper.Candle {
+	return paper.Candle{Time: minute(m), Open: open, High: high, Low: low, Close: close}
+}
+
+// descending is a plot falling by 1 every minute from 100 at minute 0
+var descending = &geometry.Line{A: -1.0 / 60, B: 100}
+
+func TestRun_Buy(t *testing.T) {
+	candles := []paper.Candle{
+		candle(0, 105, 106, 104, 105),
+		candle(1, 105, 105, 100.5, 101),
+		// the order rests at 98 and the low reaches it
+		candle(2, 101, 101, 97.5, 98),
+		candle(3, 99, 103, 98, 102),
+	}
+
+	report, err := backtest.Run(candles, backtest.Config{Plot: descending, Interval: 2 * time.Minute, Side: paper.SIDE_BUY, Quantity: 2, Fee: 0.01})
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.StatusFilled, report.Status)
+	assert.Equal(t, 2, report.Ticks)
+	assert.Equal(t, 2*time.Minute, report.TimeToFill)
+	assert.Equal(t, &backtest.Fill{Time: minute(2), Price: 98, PlotPrice: 98, Fee: 1.96}, report.Fill)
+	assert.InDelta(t, (102-98)*2-1.96, report.PnL, 1e-9)
+}
+
+func TestRun_GapFill(t *testing.T) {
+	candles := []paper.Candle{
+		candle(0, 105, 106, 104, 105),
+		candle(1, 95, 96, 94, 95),
+		candle(2, 95, 95, 90, 92),
+	}
+
+	// the order rests at 100 since the start and the second candle opens below it
+	report, err := backtest.Run(candles, backtest.Config{Plot: descending, Interval: time.Hour, Side: paper.SIDE_BUY, Quantity: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.StatusFilled, report.Status)
+	assert.Equal(t, 1, report.Ticks)
+	assert.Equal(t, 95.0, report.Fill.Price)
+	assert.InDelta(t, 99, report.Fill.PlotPrice, 1e-9)
+	assert.InDelta(t, -4, report.Fill.Slippage, 1e-9)
+}
+
+func TestRun_Sell(t *testing.T) {
+	candles := []paper.Candle{
+		candle(0, 95, 96, 94, 95),
+		candle(1, 95, 100.5, 95, 96),
+	}
+
+	report, err := backtest.Run(candles, backtest.Config{Plot: &geometry.Line{B: 100}, Interval: time.Minute, Side: paper.SIDE_SELL, Quantity: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.StatusFilled, report.Status)
+	assert.Equal(t, 100.0, report.Fill.Price)
+	assert.Equal(t, 0.0, report.Fill.Slippage)
+	assert.Equal(t, 4.0, report.PnL)
+}
+
+func TestRun_NotFilled(t *testing.T) {
+	candles := []paper.Candle{
+		candle(0, 105, 106, 104, 105),
+		candle(1, 105, 106, 104, 105),
+	}
+
+	report, err := backtest.Run(candles, backtest.Config{Plot: descending, Interval: time.Minute, Side: paper.SIDE_BUY, Quantity: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.StatusRunning, report.Status)
+	assert.Nil(t, report.Fill)
+	assert.Equal(t, 0.0, report.PnL)
+}
+
+func TestRun_PlotOutOfRange(t *testing.T) {
+	candles := []paper.Candle{
+		candle(0, 105, 106, 104, 105),
+		candle(1, 105, 106, 104, 105),
+		// the order left at 100 is crossed after the plot went out of range
+		candle(2, 101, 101, 99, 100),
+	}
+
+	// the plot ends at the second tick, the order rests at 100 since the start
+	plot := geometry.NewSchedule(time.Time{}, minute(1), descending)
+
+	tests := []struct {
+		policy plotor.OutOfRangePolicy
+		status plotor.Status
+		fill   *backtest.Fill
+		err    error
+	}{
+		{policy: "", status: plotor.StatusFilled, fill: &backtest.Fill{Time: minute(2), Price: 100, PlotPrice: 100}, err: geometry.ErrOutOfRange},
+		{policy: plotor.OutOfRangeLeave, status: plotor.StatusFilled, fill: &backtest.Fill{Time: minute(2), Price: 100, PlotPrice: 100}, err: geometry.ErrOutOfRange},
+		{policy: plotor.OutOfRangeWait, status: plotor.StatusFilled, fill: &backtest.Fill{Time: minute(2), Price: 100, PlotPrice: 100}},
+		{policy: plotor.OutOfRangeCancel, status: plotor.StatusStopped, err: geometry.ErrOutOfRange},
+		{policy: plotor.OutOfRangeMarket, status: plotor.StatusFilled, fill: &backtest.Fill{Time: minute(1), Price: 105, PlotPrice: 100, Slippage: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			report, err := backtest.Run(candles, backtest.Config{Plot: plot, Interval: time.Minute, Side: paper.SIDE_BUY, Quantity: 1, OutOfRange: tt.policy})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, report.Status)
+			assert.Equal(t, tt.fill, report.Fill)
+			assert.Equal(t, 1, report.Ticks)
+
+			if tt.err != nil {
+				assert.ErrorIs(t, report.Err, tt.err)
+			} else {
+				assert.NoError(t, report.Err)
+			}
+		})
+	}
+}
+
+func TestRun_Invalid(t *testing.T) {
+	candles := []paper.Candle{
+		candle(0, 105, 106, 104, 105),
+	}
+
+	// the order can not be placed when the plot has no price at the start
+	report, err := backtest.Run(candles, backtest.Config{Plot: geometry.NewSchedule(minute(5), time.Time{}, descending), Interval: time.Minute, Side: paper.SIDE_BUY, Quantity: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.StatusFailed, report.Status)
+	assert.ErrorIs(t, report.Err, geometry.ErrOutOfRange)
+	assert.Equal(t, 0, report.Ticks)
+
+	_, err = backtest.Run(nil, backtest.Config{Plot: descending, Interval: time.Minute, Side: paper.SIDE_BUY, Quantity: 1})
+	assert.Error(t, err)
+
+	_, err = backtest.Run(candles, backtest.Config{Plot: descending, Interval: time.Minute, Side: "HOLD", Quantity: 1})
+	assert.Error(t, err)
+
+	_, err = backtest.Run(candles, backtest.Config{Plot: descending, Interval: time.Minute, Side: paper.SIDE_BUY, Quantity: 1, OutOfRange: "IGNORE"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// transientCodes are codes of binance errors that may not happen again when the request is repeated
+var transientCodes = map[int64]bool{
+	-1000: true, // UNKNOWN
+	-1001: true, // DISCONNECTED
+	-1003: true, // TOO_MANY_REQUESTS
+	-1006: true, // UNEXPECTED_RESP
+	-1007: true, // TIMEOUT
+	-1008: true, // SERVER_BUSY
+	-1021: true, // INVALID_TIMESTAMP
+}
+
+// classify marks binance API errors with plotor.ErrTransient or plotor.ErrPermanent,
+// errors without a code come from responses that could not be parsed (e.g. 5xx) and are transient
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	apiErr := &common.APIError{}
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	if apiErr.Code == 0 || transientCodes[apiErr.Code] {
+		return fmt.Errorf("%w: %w", plotor.ErrTransient, err)
+	}
+
+	return fmt.Errorf("%w: %w", plotor.ErrPermanent, err)
+}
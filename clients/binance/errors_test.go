@@ -0,0 +1,36 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server busy", &common.APIError{Code: -1008, Message: "Server is currently overloaded"}, true},
+		{"unparsed response", &common.APIError{}, true},
+		{"insufficient balance", &common.APIError{Code: -2010, Message: "Account has insufficient balance"}, false},
+		{"wrapped", fmt.Errorf("error creating order: %w", &common.APIError{Code: -1007}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classify(tt.err)
+			assert.Equal(t, tt.want, plotor.IsTransient(err))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	other := errors.New("unexpected order type")
+	assert.Equal(t, other, classify(other))
+	assert.Nil(t, classify(nil))
+}
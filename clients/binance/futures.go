@@ -21,6 +21,22 @@ func init() {
 
 var FUTURES_EXCHANGEINFO_FILENAME = "futures_exchange_info.json"
 
+// futuresStopOrderTypes are triggered at a stop price, the plot price is used as the stop price
+// and as the limit price of stop limit orders
+var futuresStopOrderTypes = map[futures.OrderType]bool{
+	futures.OrderTypeStop:             true,
+	futures.OrderTypeStopMarket:       true,
+	futures.OrderTypeTakeProfit:       true,
+	futures.OrderTypeTakeProfitMarket: true,
+}
+
+// futuresMarketOrderTypes have no limit price, they are rejected when price or time in force is set
+var futuresMarketOrderTypes = map[futures.OrderType]bool{
+	futures.OrderTypeMarket:           true,
+	futures.OrderTypeStopMarket:       true,
+	futures.OrderTypeTakeProfitMarket: true,
+}
+
 // FuturesOrderRequest holds fields that are required (or supported) to create an order
 type FuturesOrderRequest struct {
 	Symbol        string                  `json:"symbol"`
@@ -75,6 +91,29 @@ func (o *FuturesOrder) Details() (map[string]any, error) {
 	return m, nil
 }
 
+func (o *FuturesOrder) OrderStatus() plotor.OrderStatus {
+	return orderStatus(string(o.Status))
+}
+
+func (o *FuturesOrder) ExchangeOrderID() string {
+	return strconv.FormatInt(o.OrderID, 10)
+}
+
+func (o *FuturesOrder) OrderSymbol() string {
+	return o.Symbol
+}
+
+// OrderPrice returns the limit price of the order or the stop price of stop market orders, zero if it can not be parsed
+func (o *FuturesOrder) OrderPrice() float64 {
+	if futuresStopOrderTypes[o.Type] && futuresMarketOrderTypes[o.Type] {
+		price, _ := strconv.ParseFloat(o.StopPrice, 64)
+		return price
+	}
+
+	price, _ := strconv.ParseFloat(o.Price, 64)
+	return price
+}
+
 type FuturesCredentials struct {
 	API_KEY, SECRET_KEY string
 }
@@ -116,7 +155,7 @@ func (s *FuturesClient) getOrder(ctx context.Context, order plotor.ClientOrder)
 
 	res, err := s.sdkClient.NewGetOrderService().OrderID(req.OrderID).Symbol(req.Symbol).Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, classify(err)
 	}
 
 	return &FuturesOrder{
@@ -197,13 +236,20 @@ func (e *FuturesClient) createOrder(ctx context.Context, req *FuturesOrderReques
 		Side(futures.SideType(req.Side)).
 		Type(futures.OrderType(req.OrderType)).
 		Symbol(req.Symbol).
-		Price(fmt.Sprint(req.price)).
-		Quantity(fmt.Sprint(baseQuantity(req.price, req.BaseQuantity, req.QuoteQuantity))).
-		TimeInForce(futures.TimeInForceType(req.TimeInForce))
+		Quantity(fmt.Sprint(baseQuantity(req.price, req.BaseQuantity, req.QuoteQuantity)))
+
+	if !futuresMarketOrderTypes[req.OrderType] {
+		orderSvc.Price(fmt.Sprint(req.price)).
+			TimeInForce(futures.TimeInForceType(req.TimeInForce))
+	}
+
+	if futuresStopOrderTypes[req.OrderType] {
+		orderSvc.StopPrice(fmt.Sprint(req.price))
+	}
 
 	res, err := orderSvc.Do(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error creating order: %w", err)
+		return nil, fmt.Errorf("error creating order: %w", classify(err))
 	}
 
 	return &FuturesOrder{
@@ -235,13 +281,31 @@ func (e *FuturesClient) createOrder(ctx context.Context, req *FuturesOrderReques
 }
 
 func (f *FuturesClient) UpdateOrderPrice(ctx context.Context, order plotor.ClientOrder, price float64) (plotor.ClientOrder, error) {
+	return f.replace(ctx, order, "", price)
+}
+
+// MarketOrder cancels the order and creates a market order for its remaining quantity
+func (f *FuturesClient) MarketOrder(ctx context.Context, order plotor.ClientOrder) (plotor.ClientOrder, error) {
+	newOrder, err := f.replace(ctx, order, futures.OrderTypeMarket, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetOrder(ctx, newOrder)
+}
+
+// replace cancels the order and creates a new one for its remaining quantity, empty orderType keeps the type of the order
+func (f *FuturesClient) replace(ctx context.Context, order plotor.ClientOrder, orderType futures.OrderType, price float64) (*FuturesOrder, error) {
 	o, err := f.getOrder(ctx, order)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := f.CancelOrder(ctx, order); err != nil {
-		return nil, fmt.Errorf("error cancelling order: %w", err)
+	// the order is already cancelled when a previous replacement failed to create the new order
+	if o.OrderStatus() != plotor.OrderStatusCanceled {
+		if err := f.CancelOrder(ctx, order); err != nil {
+			return nil, fmt.Errorf("error cancelling order: %w", err)
+		}
 	}
 
 	origBaseQty, err := strconv.ParseFloat(o.OrigQuantity, 64)
@@ -251,25 +315,58 @@ func (f *FuturesClient) UpdateOrderPrice(ctx context.Context, order plotor.Clien
 
 	execBaseQty, err := strconv.ParseFloat(o.ExecutedQuantity, 64)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing OrigQuantity: %w", err)
+		return nil, fmt.Errorf("error parsing ExecutedQuantity: %w", err)
+	}
+
+	if orderType == "" {
+		orderType = o.Type
 	}
 
 	or := &FuturesOrderRequest{
 		ClientOrderID: o.ClientOrderID,
 		Symbol:        o.Symbol,
 		Side:          o.Side,
-		OrderType:     o.Type,
+		OrderType:     orderType,
 		TimeInForce:   o.TimeInForce,
 		BaseQuantity:  origBaseQty - execBaseQty,
 		price:         price,
 	}
 
-	res, err := f.createOrder(ctx, or)
+	return f.createOrder(ctx, or)
+}
+
+// FilterPrice returns the price adjusted to the price filter of the symbol, it's the price an order would be placed at
+func (f *FuturesClient) FilterPrice(ctx context.Context, symbol string, price float64) (float64, error) {
+	exchangeSymbol, err := f.symbol(ctx, symbol)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+
+	pf := exchangeSymbol.PriceFilter()
+	if pf == nil {
+		return price, nil
+	}
+
+	return futuresPriceFilter(pf, price)
+}
+
+// MarketPrice returns the latest price of the symbol
+func (e *FuturesClient) MarketPrice(ctx context.Context, symbol string) (float64, error) {
+	prices, err := e.sdkClient.NewListPricesService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, classify(err)
+	}
+
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("no price of %s", symbol)
+	}
+
+	price, err := strconv.ParseFloat(prices[0].Price, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing price: %w", err)
 	}
 
-	return res, nil
+	return price, nil
 }
 
 func (e *FuturesClient) CancelOrder(ctx context.Context, order plotor.ClientOrder) error {
@@ -280,7 +377,7 @@ func (e *FuturesClient) CancelOrder(ctx context.Context, order plotor.ClientOrde
 
 	_, err := e.sdkClient.NewCancelOrderService().OrderID(o.OrderID).Symbol(o.Symbol).Do(ctx)
 
-	return err
+	return classify(err)
 }
 
 func (f *FuturesClient) symbol(ctx context.Context, symbol string) (futures.Symbol, error) {
@@ -10,37 +10,74 @@ import (
 )
 
 var futuresOrderTypeFilters = map[futures.OrderType]func(futures.Symbol, *FuturesOrderRequest) error{
-	futures.OrderTypeLimit: func(s futures.Symbol, or *FuturesOrderRequest) error {
-		// PRICE
-		if pf := s.PriceFilter(); pf != nil {
-			price, err := futuresPriceFilter(pf, or.price)
-			if err != nil {
-				return err
-			}
-
-			or.price = price
+	futures.OrderTypeLimit:            futuresLimitFilters,
+	futures.OrderTypeMarket:           futuresMarketFilters,
+	futures.OrderTypeStop:             futuresLimitFilters,
+	futures.OrderTypeStopMarket:       futuresStopMarketFilters,
+	futures.OrderTypeTakeProfit:       futuresLimitFilters,
+	futures.OrderTypeTakeProfitMarket: futuresStopMarketFilters,
+}
+
+// futuresLimitFilters filters orders with a limit price, the stop price of stop limit orders is the limit price
+func futuresLimitFilters(s futures.Symbol, or *FuturesOrderRequest) error {
+	// PRICE
+	if pf := s.PriceFilter(); pf != nil {
+		price, err := futuresPriceFilter(pf, or.price)
+		if err != nil {
+			return err
 		}
 
-		// LOT SIZE
-		if lsf := s.LotSizeFilter(); lsf != nil {
-			qty, err := futuresLotSizeFilter(lsf, or.BaseQuantity)
-			if err != nil {
-				return err
-			}
+		or.price = price
+	}
 
-			or.BaseQuantity = qty
+	// LOT SIZE
+	if lsf := s.LotSizeFilter(); lsf != nil {
+		qty, err := futuresLotSizeFilter(lsf, or.BaseQuantity)
+		if err != nil {
+			return err
 		}
 
-		// MIN NOTIONAL
-		if mnf := s.MinNotionalFilter(); mnf != nil {
-			err := futuresMinNotionalFilter(mnf, or.price, or.BaseQuantity)
-			if err != nil {
-				return err
-			}
+		or.BaseQuantity = qty
+	}
+
+	// MIN NOTIONAL
+	if mnf := s.MinNotionalFilter(); mnf != nil {
+		err := futuresMinNotionalFilter(mnf, or.price, or.BaseQuantity)
+		if err != nil {
+			return err
 		}
+	}
 
-		return nil
-	},
+	return nil
+}
+
+func futuresMarketFilters(s futures.Symbol, or *FuturesOrderRequest) error {
+	// LOT SIZE
+	if lsf := s.LotSizeFilter(); lsf != nil {
+		qty, err := futuresLotSizeFilter(lsf, or.BaseQuantity)
+		if err != nil {
+			return err
+		}
+
+		or.BaseQuantity = qty
+	}
+
+	return nil
+}
+
+// futuresStopMarketFilters filters market orders placed at a stop price
+func futuresStopMarketFilters(s futures.Symbol, or *FuturesOrderRequest) error {
+	// PRICE, applies to the stop price
+	if pf := s.PriceFilter(); pf != nil {
+		price, err := futuresPriceFilter(pf, or.price)
+		if err != nil {
+			return err
+		}
+
+		or.price = price
+	}
+
+	return futuresMarketFilters(s, or)
 }
 
 func applyFuturesFilters(s futures.Symbol, or *FuturesOrderRequest) error {
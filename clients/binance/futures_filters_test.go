@@ -154,6 +154,57 @@ func Test_applyFuturesFilters(t *testing.T) {
 				BaseQuantity: 100000.0,
 			},
 		},
+		{
+			name: "stop market",
+			args: args{
+				s: symbolfETHBTC,
+				o: FuturesOrderRequest{
+					OrderType:    futures.OrderTypeStopMarket,
+					Symbol:       "fETHBTC",
+					price:        0.12345678912345,
+					BaseQuantity: 0.212345678912345,
+				},
+			},
+			wantErr: false,
+			exRes: FuturesOrderRequest{
+				OrderType:    futures.OrderTypeStopMarket,
+				Symbol:       "fETHBTC",
+				price:        0.123457,
+				BaseQuantity: 0.21,
+			},
+		},
+		{
+			name: "stop limit",
+			args: args{
+				s: symbolfETHBTC,
+				o: FuturesOrderRequest{
+					OrderType:    futures.OrderTypeTakeProfit,
+					Symbol:       "fETHBTC",
+					price:        0.12345678912345,
+					BaseQuantity: 0.212345678912345,
+				},
+			},
+			wantErr: false,
+			exRes: FuturesOrderRequest{
+				OrderType:    futures.OrderTypeTakeProfit,
+				Symbol:       "fETHBTC",
+				price:        0.123457,
+				BaseQuantity: 0.21,
+			},
+		},
+		{
+			name: "unsupported order type",
+			args: args{
+				s: symbolfETHBTC,
+				o: FuturesOrderRequest{
+					OrderType:    futures.OrderTypeTrailingStopMarket,
+					Symbol:       "fETHBTC",
+					price:        0.078794,
+					BaseQuantity: 0.21,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -23,6 +23,22 @@ func init() {
 
 var SPOT_EXCHANGEINFO_FILENAME = "spot_exchange_info.json"
 
+// spotStopOrderTypes are triggered at a stop price, the plot price is used as the stop price
+// and as the limit price of stop limit orders
+var spotStopOrderTypes = map[sdk.OrderType]bool{
+	sdk.OrderTypeStopLoss:        true,
+	sdk.OrderTypeStopLossLimit:   true,
+	sdk.OrderTypeTakeProfit:      true,
+	sdk.OrderTypeTakeProfitLimit: true,
+}
+
+// spotMarketOrderTypes have no limit price, they are rejected when price or time in force is set
+var spotMarketOrderTypes = map[sdk.OrderType]bool{
+	sdk.OrderTypeMarket:     true,
+	sdk.OrderTypeStopLoss:   true,
+	sdk.OrderTypeTakeProfit: true,
+}
+
 // SpotOrderRequest holds fields that are required (or supported) to create an order
 type SpotOrderRequest struct {
 	Symbol        string              `json:"symbol"`
@@ -48,7 +64,7 @@ type SpotOrder struct {
 	TimeInForce              binance.TimeInForceType `json:"timeInForce"`
 	Type                     binance.OrderType       `json:"type"`
 	Side                     binance.SideType        `json:"side"`
-	//StopPrice                string                  `json:"stopPrice"`
+	StopPrice                string                  `json:"stopPrice"`
 	//IcebergQuantity          string                  `json:"icebergQty"`
 	Time                   int64  `json:"time"`
 	UpdateTime             int64  `json:"updateTime"`
@@ -72,6 +88,29 @@ func (o *SpotOrder) Details() (map[string]any, error) {
 	return m, nil
 }
 
+func (o *SpotOrder) OrderStatus() plotor.OrderStatus {
+	return orderStatus(string(o.Status))
+}
+
+func (o *SpotOrder) ExchangeOrderID() string {
+	return strconv.FormatInt(o.OrderID, 10)
+}
+
+func (o *SpotOrder) OrderSymbol() string {
+	return o.Symbol
+}
+
+// OrderPrice returns the limit price of the order or the stop price of stop market orders, zero if it can not be parsed
+func (o *SpotOrder) OrderPrice() float64 {
+	if spotStopOrderTypes[o.Type] && spotMarketOrderTypes[o.Type] {
+		price, _ := strconv.ParseFloat(o.StopPrice, 64)
+		return price
+	}
+
+	price, _ := strconv.ParseFloat(o.Price, 64)
+	return price
+}
+
 type SpotCredentials struct {
 	API_KEY, SECRET_KEY string
 }
@@ -113,7 +152,7 @@ func (s *SpotClient) getOrder(ctx context.Context, order plotor.ClientOrder) (*S
 
 	res, err := s.sdkClient.NewGetOrderService().OrderID(req.OrderID).Symbol(req.Symbol).Do(ctx)
 	if err != nil {
-		return nil, err
+		return nil, classify(err)
 	}
 
 	return &SpotOrder{
@@ -129,7 +168,7 @@ func (s *SpotClient) getOrder(ctx context.Context, order plotor.ClientOrder) (*S
 		TimeInForce:              res.TimeInForce,
 		Type:                     res.Type,
 		Side:                     res.Side,
-		//StopPrice:                res.StopPrice,
+		StopPrice:                res.StopPrice,
 		//IcebergQuantity:          res.IcebergQuantity,
 		Time:                   res.Time,
 		UpdateTime:             res.UpdateTime,
@@ -189,13 +228,26 @@ func (e *SpotClient) createOrder(ctx context.Context, req *SpotOrderRequest) (*S
 		Side(sdk.SideType(req.Side)).
 		Type(sdk.OrderType(req.OrderType)).
 		Symbol(req.Symbol).
-		Price(fmt.Sprint(req.price)).
-		Quantity(fmt.Sprint(baseQuantity(req.price, req.BaseQuantity, req.QuoteQuantity))).
-		TimeInForce(sdk.TimeInForceType(req.TimeInForce))
+		Quantity(fmt.Sprint(baseQuantity(req.price, req.BaseQuantity, req.QuoteQuantity)))
+
+	if !spotMarketOrderTypes[req.OrderType] {
+		orderSvc.Price(fmt.Sprint(req.price)).
+			TimeInForce(sdk.TimeInForceType(req.TimeInForce))
+	}
+
+	if spotStopOrderTypes[req.OrderType] {
+		orderSvc.StopPrice(fmt.Sprint(req.price))
+	}
 
 	res, err := orderSvc.Do(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error creating order: %w", err)
+		return nil, fmt.Errorf("error creating order: %w", classify(err))
+	}
+
+	// the response does not include the stop price
+	stopPrice := ""
+	if spotStopOrderTypes[req.OrderType] {
+		stopPrice = fmt.Sprint(req.price)
 	}
 
 	return &SpotOrder{
@@ -210,17 +262,36 @@ func (e *SpotClient) createOrder(ctx context.Context, req *SpotOrderRequest) (*S
 		TimeInForce:              res.TimeInForce,
 		Type:                     res.Type,
 		Side:                     res.Side,
+		StopPrice:                stopPrice,
 	}, nil
 }
 
 func (e *SpotClient) UpdateOrderPrice(ctx context.Context, order plotor.ClientOrder, price float64) (plotor.ClientOrder, error) {
+	return e.replace(ctx, order, "", price)
+}
+
+// MarketOrder cancels the order and creates a market order for its remaining quantity
+func (e *SpotClient) MarketOrder(ctx context.Context, order plotor.ClientOrder) (plotor.ClientOrder, error) {
+	newOrder, err := e.replace(ctx, order, sdk.OrderTypeMarket, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.GetOrder(ctx, newOrder)
+}
+
+// replace cancels the order and creates a new one for its remaining quantity, empty orderType keeps the type of the order
+func (e *SpotClient) replace(ctx context.Context, order plotor.ClientOrder, orderType sdk.OrderType, price float64) (*SpotOrder, error) {
 	o, err := e.getOrder(ctx, order)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := e.CancelOrder(ctx, order); err != nil {
-		return nil, fmt.Errorf("error cancelling order: %w", err)
+	// the order is already cancelled when a previous replacement failed to create the new order
+	if o.OrderStatus() != plotor.OrderStatusCanceled {
+		if err := e.CancelOrder(ctx, order); err != nil {
+			return nil, fmt.Errorf("error cancelling order: %w", err)
+		}
 	}
 
 	origBaseQty, err := strconv.ParseFloat(o.OrigQuantity, 64)
@@ -230,25 +301,58 @@ func (e *SpotClient) UpdateOrderPrice(ctx context.Context, order plotor.ClientOr
 
 	execBaseQty, err := strconv.ParseFloat(o.ExecutedQuantity, 64)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing OrigQuantity: %w", err)
+		return nil, fmt.Errorf("error parsing ExecutedQuantity: %w", err)
+	}
+
+	if orderType == "" {
+		orderType = o.Type
 	}
 
 	or := &SpotOrderRequest{
 		ClientOrderID: o.ClientOrderID,
 		Symbol:        o.Symbol,
 		Side:          o.Side,
-		OrderType:     o.Type,
+		OrderType:     orderType,
 		TimeInForce:   o.TimeInForce,
 		BaseQuantity:  origBaseQty - execBaseQty,
 		price:         price,
 	}
 
-	res, err := e.createOrder(ctx, or)
+	return e.createOrder(ctx, or)
+}
+
+// FilterPrice returns the price adjusted to the price filter of the symbol, it's the price an order would be placed at
+func (e *SpotClient) FilterPrice(ctx context.Context, symbol string, price float64) (float64, error) {
+	exchangeSymbol, err := e.symbol(ctx, symbol)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+
+	pf := exchangeSymbol.PriceFilter()
+	if pf == nil {
+		return price, nil
+	}
+
+	return spotPriceFilter(pf, price)
+}
+
+// MarketPrice returns the latest price of the symbol
+func (e *SpotClient) MarketPrice(ctx context.Context, symbol string) (float64, error) {
+	prices, err := e.sdkClient.NewListPricesService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, classify(err)
+	}
+
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("no price of %s", symbol)
+	}
+
+	price, err := strconv.ParseFloat(prices[0].Price, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing price: %w", err)
 	}
 
-	return res, nil
+	return price, nil
 }
 
 func (e *SpotClient) CancelOrder(ctx context.Context, order plotor.ClientOrder) error {
@@ -259,7 +363,7 @@ func (e *SpotClient) CancelOrder(ctx context.Context, order plotor.ClientOrder)
 
 	_, err := e.sdkClient.NewCancelOrderService().OrderID(o.OrderID).Symbol(o.Symbol).Do(ctx)
 
-	return err
+	return classify(err)
 }
 
 func (c *SpotClient) symbol(ctx context.Context, symbol string) (sdk.Symbol, error) {
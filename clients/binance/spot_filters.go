@@ -10,37 +10,74 @@ import (
 )
 
 var spotOrderTypeFilters = map[binanceSDK.OrderType]func(binanceSDK.Symbol, *SpotOrderRequest) error{
-	binanceSDK.OrderTypeLimit: func(s binanceSDK.Symbol, or *SpotOrderRequest) error {
-		// PRICE
-		if pf := s.PriceFilter(); pf != nil {
-			price, err := spotPriceFilter(pf, or.price)
-			if err != nil {
-				return err
-			}
-
-			or.price = price
+	binanceSDK.OrderTypeLimit:           spotLimitFilters,
+	binanceSDK.OrderTypeMarket:          spotMarketFilters,
+	binanceSDK.OrderTypeStopLoss:        spotStopMarketFilters,
+	binanceSDK.OrderTypeStopLossLimit:   spotLimitFilters,
+	binanceSDK.OrderTypeTakeProfit:      spotStopMarketFilters,
+	binanceSDK.OrderTypeTakeProfitLimit: spotLimitFilters,
+}
+
+// spotLimitFilters filters orders with a limit price, the stop price of stop limit orders is the limit price
+func spotLimitFilters(s binanceSDK.Symbol, or *SpotOrderRequest) error {
+	// PRICE
+	if pf := s.PriceFilter(); pf != nil {
+		price, err := spotPriceFilter(pf, or.price)
+		if err != nil {
+			return err
 		}
 
-		// LOT SIZE
-		if lsf := s.LotSizeFilter(); lsf != nil {
-			qty, err := spotLotSizeFilter(lsf, or.BaseQuantity)
-			if err != nil {
-				return err
-			}
+		or.price = price
+	}
 
-			or.BaseQuantity = qty
+	// LOT SIZE
+	if lsf := s.LotSizeFilter(); lsf != nil {
+		qty, err := spotLotSizeFilter(lsf, or.BaseQuantity)
+		if err != nil {
+			return err
 		}
 
-		// MIN NOTIONAL
-		if mnf := s.MinNotionalFilter(); mnf != nil {
-			err := spotMinNotionalFilter(mnf, or.price, or.BaseQuantity)
-			if err != nil {
-				return err
-			}
+		or.BaseQuantity = qty
+	}
+
+	// MIN NOTIONAL
+	if mnf := s.MinNotionalFilter(); mnf != nil {
+		err := spotMinNotionalFilter(mnf, or.price, or.BaseQuantity)
+		if err != nil {
+			return err
 		}
+	}
 
-		return nil
-	},
+	return nil
+}
+
+func spotMarketFilters(s binanceSDK.Symbol, or *SpotOrderRequest) error {
+	// LOT SIZE
+	if lsf := s.LotSizeFilter(); lsf != nil {
+		qty, err := spotLotSizeFilter(lsf, or.BaseQuantity)
+		if err != nil {
+			return err
+		}
+
+		or.BaseQuantity = qty
+	}
+
+	return nil
+}
+
+// spotStopMarketFilters filters market orders placed at a stop price
+func spotStopMarketFilters(s binanceSDK.Symbol, or *SpotOrderRequest) error {
+	// PRICE, applies to the stop price
+	if pf := s.PriceFilter(); pf != nil {
+		price, err := spotPriceFilter(pf, or.price)
+		if err != nil {
+			return err
+		}
+
+		or.price = price
+	}
+
+	return spotMarketFilters(s, or)
 }
 
 func applySpotFilters(s binanceSDK.Symbol, or *SpotOrderRequest) error {
@@ -154,6 +154,74 @@ func Test_applyFilters(t *testing.T) {
 				BaseQuantity: 100000.0,
 			},
 		},
+		{
+			name: "market",
+			args: args{
+				s: symbolETHBTC,
+				o: SpotOrderRequest{
+					OrderType:    binanceSDK.OrderTypeMarket,
+					Symbol:       "ETHBTC",
+					BaseQuantity: 0.212345678912345,
+				},
+			},
+			wantErr: false,
+			exRes: SpotOrderRequest{
+				OrderType:    binanceSDK.OrderTypeMarket,
+				Symbol:       "ETHBTC",
+				BaseQuantity: 0.21,
+			},
+		},
+		{
+			name: "stop market",
+			args: args{
+				s: symbolETHBTC,
+				o: SpotOrderRequest{
+					OrderType:    binanceSDK.OrderTypeStopLoss,
+					Symbol:       "ETHBTC",
+					price:        0.12345678912345,
+					BaseQuantity: 0.212345678912345,
+				},
+			},
+			wantErr: false,
+			exRes: SpotOrderRequest{
+				OrderType:    binanceSDK.OrderTypeStopLoss,
+				Symbol:       "ETHBTC",
+				price:        0.123457,
+				BaseQuantity: 0.21,
+			},
+		},
+		{
+			name: "stop limit",
+			args: args{
+				s: symbolETHBTC,
+				o: SpotOrderRequest{
+					OrderType:    binanceSDK.OrderTypeTakeProfitLimit,
+					Symbol:       "ETHBTC",
+					price:        0.12345678912345,
+					BaseQuantity: 0.212345678912345,
+				},
+			},
+			wantErr: false,
+			exRes: SpotOrderRequest{
+				OrderType:    binanceSDK.OrderTypeTakeProfitLimit,
+				Symbol:       "ETHBTC",
+				price:        0.123457,
+				BaseQuantity: 0.21,
+			},
+		},
+		{
+			name: "unsupported order type",
+			args: args{
+				s: symbolETHBTC,
+				o: SpotOrderRequest{
+					OrderType:    binanceSDK.OrderTypeLimitMaker,
+					Symbol:       "ETHBTC",
+					price:        0.078794,
+					BaseQuantity: 0.21,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,23 @@
+package binance
+
+import "github.com/H3Cki/Plotor/plotor"
+
+// orderStatus maps binance spot and futures order statuses to plotor.OrderStatus
+func orderStatus(status string) plotor.OrderStatus {
+	switch status {
+	case "NEW", "PENDING_CANCEL":
+		return plotor.OrderStatusNew
+	case "PARTIALLY_FILLED":
+		return plotor.OrderStatusPartiallyFilled
+	case "FILLED":
+		return plotor.OrderStatusFilled
+	case "CANCELED":
+		return plotor.OrderStatusCanceled
+	case "EXPIRED", "EXPIRED_IN_MATCH":
+		return plotor.OrderStatusExpired
+	case "REJECTED":
+		return plotor.OrderStatusRejected
+	}
+
+	return plotor.OrderStatusUnknown
+}
@@ -0,0 +1,34 @@
+package binance
+
+import (
+	"testing"
+
+	"github.com/H3Cki/Plotor/plotor"
+	binanceSDK "github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_orderStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   plotor.OrderStatus
+	}{
+		{string(binanceSDK.OrderStatusTypeNew), plotor.OrderStatusNew},
+		{string(binanceSDK.OrderStatusTypePartiallyFilled), plotor.OrderStatusPartiallyFilled},
+		{string(binanceSDK.OrderStatusTypeFilled), plotor.OrderStatusFilled},
+		{string(binanceSDK.OrderStatusTypeCanceled), plotor.OrderStatusCanceled},
+		{string(binanceSDK.OrderStatusTypePendingCancel), plotor.OrderStatusNew},
+		{string(binanceSDK.OrderStatusTypeRejected), plotor.OrderStatusRejected},
+		{string(binanceSDK.OrderStatusTypeExpired), plotor.OrderStatusExpired},
+		{string(futures.OrderStatusTypeExpired), plotor.OrderStatusExpired},
+		{string(futures.OrderStatusTypeNewInsurance), plotor.OrderStatusUnknown},
+		{"", plotor.OrderStatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.want, orderStatus(tt.status))
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package paper
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// FORMAT_TRADES is a CSV where every row is a trade: time,price[,...]
+	FORMAT_TRADES = "trades"
+	// FORMAT_KLINES is a CSV where every row is a candle in binance kline format: open time,open,high,low,close[,volume,...]
+	FORMAT_KLINES = "klines"
+)
+
+// Candle is a price summary of a period starting at Time, trades are candles with all prices equal
+type Candle struct {
+	Time                   time.Time
+	Open, High, Low, Close float64
+	Volume                 float64
+}
+
+// Feed holds price history of a single symbol sorted by time
+type Feed struct {
+	Candles []Candle
+}
+
+// NewFeed is a constructor for Feed, it sorts the candles by time
+func NewFeed(candles []Candle) *Feed {
+	sort.SliceStable(candles, func(i, j int) bool { return candles[i].Time.Before(candles[j].Time) })
+	return &Feed{Candles: candles}
+}
+
+// FeedPath resolves the name of a feed file against dir, the name has to be a relative path that stays inside dir
+func FeedPath(dir, name string) (string, error) {
+	if name == "" {
+		return "", errors.New("feed file can not be empty")
+	}
+
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("feed file %s must be a relative path", name)
+	}
+
+	for _, part := range strings.Split(filepath.ToSlash(name), "/") {
+		if part == ".." {
+			return "", fmt.Errorf("feed file %s can not contain ..", name)
+		}
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// LoadFeed reads a feed from a CSV file in given format
+func LoadFeed(filename, format string) (*Feed, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening feed file: %w", err)
+	}
+	defer f.Close()
+
+	candles, err := ReadCandles(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("error reading feed file %s: %w", filename, err)
+	}
+
+	return NewFeed(candles), nil
+}
+
+// Price returns the close price of the last candle that started before or at t
+func (f *Feed) Price(t time.Time) (float64, bool) {
+	i := sort.Search(len(f.Candles), func(i int) bool { return f.Candles[i].Time.After(t) })
+	if i == 0 {
+		return 0, false
+	}
+
+	return f.Candles[i-1].Close, true
+}
+
+// Range returns the lowest and the highest price of candles that started in (from, to]
+func (f *Feed) Range(from, to time.Time) (low, high float64, ok bool) {
+	i := sort.Search(len(f.Candles), func(i int) bool { return f.Candles[i].Time.After(from) })
+
+	for ; i < len(f.Candles) && !f.Candles[i].Time.After(to); i++ {
+		c := f.Candles[i]
+		if !ok || c.Low < low {
+			low = c.Low
+		}
+
+		if !ok || c.High > high {
+			high = c.High
+		}
+
+		ok = true
+	}
+
+	return low, high, ok
+}
+
+// ReadCandles parses CSV rows in given format, a header row is skipped if present
+func ReadCandles(r io.Reader, format string) ([]Candle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	candles := []Candle{}
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if row == 1 && isHeader(record) {
+			continue
+		}
+
+		c, err := parseCandle(record, format)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		candles = append(candles, c)
+	}
+
+	return candles, nil
+}
+
+func parseCandle(record []string, format string) (Candle, error) {
+	switch format {
+	case FORMAT_TRADES:
+		if len(record) < 2 {
+			return Candle{}, fmt.Errorf("expected at least 2 columns, got %d", len(record))
+		}
+
+		t, err := parseTime(record[0])
+		if err != nil {
+			return Candle{}, err
+		}
+
+		price, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return Candle{}, fmt.Errorf("error parsing price: %w", err)
+		}
+
+		return Candle{Time: t, Open: price, High: price, Low: price, Close: price}, nil
+	case FORMAT_KLINES:
+		if len(record) < 5 {
+			return Candle{}, fmt.Errorf("expected at least 5 columns, got %d", len(record))
+		}
+
+		t, err := parseTime(record[0])
+		if err != nil {
+			return Candle{}, err
+		}
+
+		values := make([]float64, 5)
+		for i := 1; i < len(record) && i <= 5; i++ {
+			v, err := strconv.ParseFloat(record[i], 64)
+			if err != nil {
+				return Candle{}, fmt.Errorf("error parsing column %d: %w", i+1, err)
+			}
+
+			values[i-1] = v
+		}
+
+		return Candle{Time: t, Open: values[0], High: values[1], Low: values[2], Close: values[3], Volume: values[4]}, nil
+	}
+
+	return Candle{}, fmt.Errorf("unknown feed format: %s", format)
+}
+
+// parseTime accepts RFC3339 dates and unix timestamps in seconds, milliseconds or microseconds
+func parseTime(s string) (time.Time, error) {
+	if !isNumber(s) {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error parsing time: %w", err)
+		}
+
+		return t.UTC(), nil
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error parsing time: %w", err)
+	}
+
+	switch digits := len(strings.TrimPrefix(s, "-")); {
+	case digits >= 16:
+		return time.UnixMicro(v).UTC(), nil
+	case digits >= 13:
+		return time.UnixMilli(v).UTC(), nil
+	}
+
+	return time.Unix(v, 0).UTC(), nil
+}
+
+// isHeader returns true if the first column is neither a number nor a date
+func isHeader(record []string) bool {
+	if len(record) == 0 || isNumber(record[0]) {
+		return false
+	}
+
+	_, err := time.Parse(time.RFC3339, record[0])
+
+	return err != nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
@@ -0,0 +1,477 @@
+package paper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/google/uuid"
+)
+
+const (
+	SIDE_BUY  = "BUY"
+	SIDE_SELL = "SELL"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// SymbolConfig describes a tradable symbol and the CSV file its prices are replayed from
+type SymbolConfig struct {
+	Base, Quote string
+	// File is the path of the feed file, it's resolved against Config.FeedDir when that is set
+	File string
+	// Format is either FORMAT_TRADES or FORMAT_KLINES
+	Format string
+}
+
+// Config holds everything that is required to set up a paper trading client
+type Config struct {
+	Symbols  map[string]SymbolConfig
+	Balances map[string]float64
+	// Fee is a fraction of the received asset that is charged for every fill, e.g. 0.001
+	Fee float64
+	// ReplayStart maps the moment the client is set up to this time in the feeds,
+	// when it's zero the feeds are read using the current time
+	ReplayStart time.Time
+	// FeedDir confines the feed files to a directory, files are then relative paths inside of it (see FeedPath).
+	// It's set by whoever runs the client and never read from JSON, configs coming from users must have it set.
+	FeedDir string `json:"-"`
+}
+
+// FeedError is returned by SetUp when the feed of a symbol can not be loaded,
+// Err can quote the content of the file so it should not be shown to users
+type FeedError struct {
+	Symbol string
+	Err    error
+}
+
+func (e *FeedError) Error() string {
+	return fmt.Sprintf("error loading feed for symbol %s: %v", e.Symbol, e.Err)
+}
+
+func (e *FeedError) Unwrap() error {
+	return e.Err
+}
+
+// OrderRequest holds fields that are required (or supported) to create an order
+type OrderRequest struct {
+	Symbol        string  `json:"symbol"`
+	Side          string  `json:"side"`
+	BaseQuantity  float64 `json:"baseQuantity"`
+	QuoteQuantity float64 `json:"quoteQuantity"`
+	ClientOrderID string  `json:"clientOrderID"`
+}
+
+// Order is a simulated limit order, it's always filled completely
+type Order struct {
+	Symbol           string             `json:"symbol"`
+	OrderID          int64              `json:"orderId"`
+	ClientOrderID    string             `json:"clientOrderId"`
+	Side             string             `json:"side"`
+	Price            float64            `json:"price"`
+	OrigQuantity     float64            `json:"origQty"`
+	ExecutedQuantity float64            `json:"executedQty"`
+	FillPrice        float64            `json:"fillPrice"`
+	Status           plotor.OrderStatus `json:"status"`
+	Time             time.Time          `json:"time"`
+	UpdateTime       time.Time          `json:"updateTime"`
+	// checkedAt is the feed time up to which fills were evaluated
+	checkedAt time.Time
+}
+
+func (o *Order) Details() (map[string]any, error) {
+	m := map[string]any{}
+
+	bytes, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(bytes, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (o *Order) OrderStatus() plotor.OrderStatus {
+	return o.Status
+}
+
+func (o *Order) ExchangeOrderID() string {
+	return strconv.FormatInt(o.OrderID, 10)
+}
+
+func (o *Order) OrderSymbol() string {
+	return o.Symbol
+}
+
+func (o *Order) OrderPrice() float64 {
+	return o.Price
+}
+
+// Client is a simulated exchange implementing plotor.Client, limit orders are filled when the feed price reaches them
+type Client struct {
+	symbols  map[string]SymbolConfig
+	feeds    map[string]*Feed
+	balances map[string]float64
+	orders   map[int64]*Order
+	fee      float64
+	nextID   int64
+	// feedTime maps the current time to the time in the feeds
+	feedTime func() time.Time
+	mu       *sync.Mutex
+}
+
+func (c *Client) SetUp(cfg Config) error {
+	feeds := map[string]*Feed{}
+
+	for symbol, sc := range cfg.Symbols {
+		if sc.Base == "" || sc.Quote == "" {
+			return fmt.Errorf("base and quote assets are required for symbol %s", symbol)
+		}
+
+		file := sc.File
+		if cfg.FeedDir != "" {
+			path, err := FeedPath(cfg.FeedDir, sc.File)
+			if err != nil {
+				return fmt.Errorf("invalid feed for symbol %s: %w", symbol, err)
+			}
+
+			file = path
+		}
+
+		feed, err := LoadFeed(file, sc.Format)
+		if err != nil {
+			return &FeedError{Symbol: symbol, Err: err}
+		}
+
+		feeds[symbol] = feed
+	}
+
+	c.setUp(cfg, feeds)
+
+	return nil
+}
+
+func (c *Client) setUp(cfg Config, feeds map[string]*Feed) {
+	balances := map[string]float64{}
+	for asset, amount := range cfg.Balances {
+		balances[asset] = amount
+	}
+
+	c.symbols = cfg.Symbols
+	c.feeds = feeds
+	c.balances = balances
+	c.orders = map[int64]*Order{}
+	c.fee = cfg.Fee
+	c.mu = &sync.Mutex{}
+	c.feedTime = time.Now
+
+	if !cfg.ReplayStart.IsZero() {
+		start := time.Now()
+		c.feedTime = func() time.Time { return cfg.ReplayStart.Add(time.Since(start)) }
+	}
+}
+
+// Balances returns a copy of free balances
+func (c *Client) Balances() map[string]float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	balances := map[string]float64{}
+	for asset, amount := range c.balances {
+		balances[asset] = amount
+	}
+
+	return balances
+}
+
+func (c *Client) CreateOrder(ctx context.Context, orderData any, price float64) (plotor.ClientOrder, error) {
+	req := &OrderRequest{}
+
+	switch v := orderData.(type) {
+	case *OrderRequest:
+		req = v
+	case OrderRequest:
+		req = &v
+	case []byte:
+		if err := json.Unmarshal(v, req); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal order data: %w", err)
+		}
+	case json.RawMessage:
+		if err := json.Unmarshal(v, req); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal order data: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected order data type: %v", v)
+	}
+
+	if req.ClientOrderID == "" {
+		req.ClientOrderID = uuid.NewString()
+	}
+
+	qty := req.BaseQuantity
+	if qty == 0 && price != 0 {
+		qty = req.QuoteQuantity / price
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	order, err := c.createOrder(req.Symbol, req.Side, req.ClientOrderID, price, qty)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := *order
+	return &cp, nil
+}
+
+func (c *Client) GetOrder(ctx context.Context, order plotor.ClientOrder) (plotor.ClientOrder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, err := c.order(order)
+	if err != nil {
+		return nil, err
+	}
+
+	c.sync(o)
+
+	cp := *o
+	return &cp, nil
+}
+
+// UpdateOrderPrice cancels the order and creates a new one for the remaining quantity, a cancelled order is only re-created
+// so a replacement that failed after the cancel can be repeated, other closed orders are returned unchanged
+func (c *Client) UpdateOrderPrice(ctx context.Context, order plotor.ClientOrder, price float64) (plotor.ClientOrder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, err := c.order(order)
+	if err != nil {
+		return nil, err
+	}
+
+	c.sync(o)
+
+	if o.Status.Closed() && o.Status != plotor.OrderStatusCanceled {
+		cp := *o
+		return &cp, nil
+	}
+
+	if o.Status != plotor.OrderStatusCanceled {
+		c.cancel(o)
+	}
+
+	newOrder, err := c.createOrder(o.Symbol, o.Side, o.ClientOrderID, price, o.OrigQuantity-o.ExecutedQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := *newOrder
+	return &cp, nil
+}
+
+// MarketOrder cancels the order and fills its remaining quantity at the current feed price, a cancelled order is only replaced
+func (c *Client) MarketOrder(ctx context.Context, order plotor.ClientOrder) (plotor.ClientOrder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, err := c.order(order)
+	if err != nil {
+		return nil, err
+	}
+
+	c.sync(o)
+
+	if o.Status.Closed() && o.Status != plotor.OrderStatusCanceled {
+		cp := *o
+		return &cp, nil
+	}
+
+	price, ok := c.feeds[o.Symbol].Price(c.feedTime())
+	if !ok {
+		return nil, fmt.Errorf("no price of %s to fill a market order at", o.Symbol)
+	}
+
+	if o.Status != plotor.OrderStatusCanceled {
+		c.cancel(o)
+	}
+
+	// the order is marketable at the current price so it's filled right away
+	newOrder, err := c.createOrder(o.Symbol, o.Side, o.ClientOrderID, price, o.OrigQuantity-o.ExecutedQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := *newOrder
+	return &cp, nil
+}
+
+// MarketPrice returns the close of the feed candle at the current feed time
+func (c *Client) MarketPrice(ctx context.Context, symbol string) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	feed, ok := c.feeds[symbol]
+	if !ok {
+		return 0, fmt.Errorf("unsupported symbol: %s", symbol)
+	}
+
+	price, ok := feed.Price(c.feedTime())
+	if !ok {
+		return 0, fmt.Errorf("no price of %s", symbol)
+	}
+
+	return price, nil
+}
+
+func (c *Client) CancelOrder(ctx context.Context, order plotor.ClientOrder) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, err := c.order(order)
+	if err != nil {
+		return err
+	}
+
+	c.sync(o)
+
+	if o.Status.Closed() {
+		return fmt.Errorf("order %d is %s", o.OrderID, o.Status)
+	}
+
+	c.cancel(o)
+
+	return nil
+}
+
+func (c *Client) order(order plotor.ClientOrder) (*Order, error) {
+	req, ok := order.(*Order)
+	if !ok {
+		return nil, fmt.Errorf("unexpected order type: %v", order)
+	}
+
+	o, ok := c.orders[req.OrderID]
+	if !ok {
+		return nil, fmt.Errorf("order %d does not exist", req.OrderID)
+	}
+
+	return o, nil
+}
+
+// createOrder locks the balance required by the order and fills it right away at the current price if the order is marketable
+func (c *Client) createOrder(symbol, side, clientOrderID string, price, qty float64) (*Order, error) {
+	sc, ok := c.symbols[symbol]
+	if !ok {
+		return nil, fmt.Errorf("unknown symbol: %s", symbol)
+	}
+
+	if price <= 0 || qty <= 0 {
+		return nil, fmt.Errorf("price and quantity must be positive, got price %f and quantity %f", price, qty)
+	}
+
+	switch side {
+	case SIDE_BUY:
+		if c.balances[sc.Quote] < price*qty {
+			return nil, fmt.Errorf("%w: %s %f required, %f available", ErrInsufficientBalance, sc.Quote, price*qty, c.balances[sc.Quote])
+		}
+
+		c.balances[sc.Quote] -= price * qty
+	case SIDE_SELL:
+		if c.balances[sc.Base] < qty {
+			return nil, fmt.Errorf("%w: %s %f required, %f available", ErrInsufficientBalance, sc.Base, qty, c.balances[sc.Base])
+		}
+
+		c.balances[sc.Base] -= qty
+	default:
+		return nil, fmt.Errorf("unsupported side: %s", side)
+	}
+
+	now := c.feedTime()
+	c.nextID++
+
+	o := &Order{
+		Symbol:        symbol,
+		OrderID:       c.nextID,
+		ClientOrderID: clientOrderID,
+		Side:          side,
+		Price:         price,
+		OrigQuantity:  qty,
+		Status:        plotor.OrderStatusNew,
+		Time:          now,
+		UpdateTime:    now,
+		checkedAt:     now,
+	}
+
+	c.orders[o.OrderID] = o
+
+	if market, ok := c.feeds[symbol].Price(now); ok {
+		if (side == SIDE_BUY && market <= price) || (side == SIDE_SELL && market >= price) {
+			c.fill(o, market, now)
+		}
+	}
+
+	return o, nil
+}
+
+// sync fills the order if the feed price reached it since the last check
+func (c *Client) sync(o *Order) {
+	if o.Status.Closed() {
+		return
+	}
+
+	now := c.feedTime()
+	low, high, ok := c.feeds[o.Symbol].Range(o.checkedAt, now)
+	o.checkedAt = now
+
+	if !ok {
+		return
+	}
+
+	if (o.Side == SIDE_BUY && low <= o.Price) || (o.Side == SIDE_SELL && high >= o.Price) {
+		c.fill(o, o.Price, now)
+	}
+}
+
+// fill executes the remaining quantity of the order at given price and releases the unused part of the locked balance
+func (c *Client) fill(o *Order, price float64, t time.Time) {
+	sc := c.symbols[o.Symbol]
+	qty := o.OrigQuantity - o.ExecutedQuantity
+
+	switch o.Side {
+	case SIDE_BUY:
+		c.balances[sc.Quote] += (o.Price - price) * qty
+		c.balances[sc.Base] += qty * (1 - c.fee)
+	case SIDE_SELL:
+		c.balances[sc.Quote] += price * qty * (1 - c.fee)
+	}
+
+	o.ExecutedQuantity = o.OrigQuantity
+	o.FillPrice = price
+	o.Status = plotor.OrderStatusFilled
+	o.UpdateTime = t
+}
+
+// cancel releases the balance locked by the unfilled part of the order
+func (c *Client) cancel(o *Order) {
+	sc := c.symbols[o.Symbol]
+	qty := o.OrigQuantity - o.ExecutedQuantity
+
+	switch o.Side {
+	case SIDE_BUY:
+		c.balances[sc.Quote] += o.Price * qty
+	case SIDE_SELL:
+		c.balances[sc.Base] += qty
+	}
+
+	o.Status = plotor.OrderStatusCanceled
+	o.UpdateTime = c.feedTime()
+}
@@ -0,0 +1,201 @@
+package paper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCandles(t *testing.T) {
+	trades := "time,price,qty\n2023-01-15T00:00:00Z,100,1\n1673740860000,101.5,2\n"
+
+	candles, err := ReadCandles(strings.NewReader(trades), FORMAT_TRADES)
+	assert.NoError(t, err)
+	assert.Equal(t, []Candle{
+		{Time: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Open: 100, High: 100, Low: 100, Close: 100},
+		{Time: time.Date(2023, 1, 15, 0, 1, 0, 0, time.UTC), Open: 101.5, High: 101.5, Low: 101.5, Close: 101.5},
+	}, candles)
+
+	klines := "1673740800,100,110,90,105,12.5,1673740859999\n"
+
+	candles, err = ReadCandles(strings.NewReader(klines), FORMAT_KLINES)
+	assert.NoError(t, err)
+	assert.Equal(t, []Candle{
+		{Time: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Open: 100, High: 110, Low: 90, Close: 105, Volume: 12.5},
+	}, candles)
+
+	_, err = ReadCandles(strings.NewReader("1673740800,abc\n"), FORMAT_TRADES)
+	assert.Error(t, err)
+
+	_, err = ReadCandles(strings.NewReader(klines), "ticks")
+	assert.Error(t, err)
+}
+
+func TestFeedPath(t *testing.T) {
+	path, err := FeedPath("feeds", "btc/trades.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("feeds", "btc", "trades.csv"), path)
+
+	for _, name := range []string{"", "/etc/passwd", "../secret.csv", "btc/../../secret.csv"} {
+		_, err := FeedPath("feeds", name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestClient_SetUpFeedDir(t *testing.T) {
+	c := &Client{}
+	err := c.SetUp(Config{
+		Symbols: map[string]SymbolConfig{"BTCUSDT": {Base: "BTC", Quote: "USDT", File: "../go.mod", Format: FORMAT_TRADES}},
+		FeedDir: t.TempDir(),
+	})
+	assert.ErrorContains(t, err, "can not contain ..")
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "btc.csv"), []byte("1673740800,secret\n"), 0600))
+
+	// the error of an unreadable feed is marked so the content of the file is not shown to users
+	err = c.SetUp(Config{
+		Symbols: map[string]SymbolConfig{"BTCUSDT": {Base: "BTC", Quote: "USDT", File: "btc.csv", Format: FORMAT_TRADES}},
+		FeedDir: dir,
+	})
+
+	var feedErr *FeedError
+	assert.ErrorAs(t, err, &feedErr)
+	assert.Equal(t, "BTCUSDT", feedErr.Symbol)
+}
+
+// newTestClient returns a client trading BTCUSDT and a function moving its feed time
+func newTestClient(t *testing.T, balances map[string]float64, candles ...Candle) (*Client, func(time.Time)) {
+	t.Helper()
+
+	c := &Client{}
+	c.setUp(Config{
+		Symbols:  map[string]SymbolConfig{"BTCUSDT": {Base: "BTC", Quote: "USDT"}},
+		Balances: balances,
+	}, map[string]*Feed{"BTCUSDT": NewFeed(candles)})
+
+	now := candles[0].Time
+	c.feedTime = func() time.Time { return now }
+
+	return c, func(t time.Time) { now = t }
+}
+
+func candle(sec int64, low, high, close float64) Candle {
+	return Candle{Time: time.Unix(sec, 0), Open: close, High: high, Low: low, Close: close}
+}
+
+func TestClient_LimitBuy(t *testing.T) {
+	ctx := context.Background()
+	c, setTime := newTestClient(t, map[string]float64{"USDT": 1000},
+		candle(0, 99, 101, 100),
+		candle(60, 97, 100, 98),
+		candle(120, 94, 99, 95),
+	)
+
+	order, err := c.CreateOrder(ctx, &OrderRequest{Symbol: "BTCUSDT", Side: SIDE_BUY, BaseQuantity: 2}, 96)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.OrderStatusNew, order.OrderStatus())
+	assert.Equal(t, map[string]float64{"USDT": 808}, c.Balances())
+
+	// the low of the second candle is above the limit price
+	setTime(time.Unix(60, 0))
+	order, err = c.UpdateOrderPrice(ctx, order, 95)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.OrderStatusNew, order.OrderStatus())
+	assert.Equal(t, 95.0, order.(*Order).Price)
+	assert.Equal(t, map[string]float64{"USDT": 810}, c.Balances())
+
+	setTime(time.Unix(120, 0))
+	order, err = c.GetOrder(ctx, order)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.OrderStatusFilled, order.OrderStatus())
+	assert.Equal(t, 95.0, order.(*Order).FillPrice)
+	assert.Equal(t, map[string]float64{"USDT": 810, "BTC": 2}, c.Balances())
+
+	// filled orders are neither replaced nor cancelled
+	same, err := c.UpdateOrderPrice(ctx, order, 90)
+	assert.NoError(t, err)
+	assert.Equal(t, order, same)
+	assert.Error(t, c.CancelOrder(ctx, order))
+}
+
+func TestClient_MarketableSell(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, map[string]float64{"BTC": 1}, candle(0, 99, 101, 100))
+	c.fee = 0.01
+
+	order, err := c.CreateOrder(ctx, []byte(`{"symbol": "BTCUSDT", "side": "SELL", "baseQuantity": 1}`), 90)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.OrderStatusFilled, order.OrderStatus())
+	assert.Equal(t, 100.0, order.(*Order).FillPrice)
+	assert.Equal(t, map[string]float64{"BTC": 0, "USDT": 99}, c.Balances())
+}
+
+func TestClient_CancelOrder(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, map[string]float64{"USDT": 100}, candle(0, 99, 101, 100))
+
+	_, err := c.CreateOrder(ctx, &OrderRequest{Symbol: "BTCUSDT", Side: SIDE_BUY, BaseQuantity: 2}, 90)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+
+	order, err := c.CreateOrder(ctx, &OrderRequest{Symbol: "BTCUSDT", Side: SIDE_BUY, QuoteQuantity: 90}, 90)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, order.(*Order).OrigQuantity)
+	assert.Equal(t, map[string]float64{"USDT": 10}, c.Balances())
+
+	assert.NoError(t, c.CancelOrder(ctx, order))
+	assert.Equal(t, map[string]float64{"USDT": 100}, c.Balances())
+
+	order, err = c.GetOrder(ctx, order)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.OrderStatusCanceled, order.OrderStatus())
+
+	// a cancelled order is re-created, e.g. when a previous replacement failed after the cancel
+	order, err = c.UpdateOrderPrice(ctx, order, 80)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.OrderStatusNew, order.OrderStatus())
+	assert.Equal(t, 1.0, order.(*Order).OrigQuantity)
+	assert.Equal(t, map[string]float64{"USDT": 20}, c.Balances())
+}
+
+func TestClient_MarketOrder(t *testing.T) {
+	ctx := context.Background()
+	c, setTime := newTestClient(t, map[string]float64{"USDT": 1000}, candle(0, 99, 101, 100), candle(60, 104, 106, 105))
+
+	order, err := c.CreateOrder(ctx, &OrderRequest{Symbol: "BTCUSDT", Side: SIDE_BUY, BaseQuantity: 1}, 90)
+	assert.NoError(t, err)
+
+	setTime(time.Unix(60, 0))
+	order, err = c.MarketOrder(ctx, order)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.OrderStatusFilled, order.OrderStatus())
+	assert.Equal(t, 105.0, order.(*Order).FillPrice)
+	assert.Equal(t, map[string]float64{"USDT": 895, "BTC": 1}, c.Balances())
+}
+
+func TestClient_MarketPrice(t *testing.T) {
+	ctx := context.Background()
+	c, setTime := newTestClient(t, map[string]float64{}, candle(60, 99, 101, 100), candle(120, 104, 106, 105))
+
+	price, err := c.MarketPrice(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, price)
+
+	setTime(time.Unix(150, 0))
+	price, err = c.MarketPrice(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 105.0, price)
+
+	setTime(time.Unix(0, 0))
+	_, err = c.MarketPrice(ctx, "BTCUSDT")
+	assert.Error(t, err)
+
+	_, err = c.MarketPrice(ctx, "ETHUSDT")
+	assert.Error(t, err)
+}
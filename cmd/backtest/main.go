@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/H3Cki/Plotor/backtest"
+	"github.com/H3Cki/Plotor/clients/paper"
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/logger"
+	"github.com/H3Cki/Plotor/plotor"
+)
+
+func main() {
+	dataFile := flag.String("data", "", "CSV file with historical prices")
+	format := flag.String("format", paper.FORMAT_KLINES, "format of the CSV file: klines or trades")
+	plotFile := flag.String("plot", "", "JSON file with the plot")
+	interval := flag.String("interval", "1h", "interval of the plot order")
+	side := flag.String("side", paper.SIDE_BUY, "side of the order: BUY or SELL")
+	qty := flag.Float64("qty", 1, "base quantity of the order")
+	fee := flag.Float64("fee", 0, "fee as a fraction of the traded value")
+	start := flag.String("start", "", "RFC3339 time to start the replay at, defaults to the first candle")
+	end := flag.String("end", "", "RFC3339 time to end the replay at, defaults to the last candle")
+	outOfRange := flag.String("out-of-range", string(plotor.OutOfRangeLeave), "out of range policy: LEAVE, CANCEL, MARKET or WAIT")
+	flag.Parse()
+
+	if *dataFile == "" || *plotFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	feed, err := paper.LoadFeed(*dataFile, *format)
+	if err != nil {
+		logger.Fatalf("error loading data: %v", err)
+	}
+
+	plotData, err := os.ReadFile(*plotFile)
+	if err != nil {
+		logger.Fatalf("error reading plot file: %v", err)
+	}
+
+	plot, err := geometry.FromJSON(plotData)
+	if err != nil {
+		logger.Fatalf("error parsing plot: %v", err)
+	}
+
+	itv, err := plotor.ParseInterval(*interval)
+	if err != nil {
+		logger.Fatalf("error parsing interval: %v", err)
+	}
+
+	cfg := backtest.Config{
+		Plot:       plot,
+		Interval:   itv,
+		Side:       *side,
+		Quantity:   *qty,
+		Fee:        *fee,
+		Start:      mustParseTime(*start),
+		End:        mustParseTime(*end),
+		OutOfRange: plotor.OutOfRangePolicy(*outOfRange),
+	}
+
+	report, err := backtest.Run(feed.Candles, cfg)
+	if err != nil {
+		logger.Fatalf("error running backtest: %v", err)
+	}
+
+	printReport(report)
+}
+
+func mustParseTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		logger.Fatalf("error parsing time %s: %v", s, err)
+	}
+
+	return t
+}
+
+func printReport(r *backtest.Report) {
+	fmt.Printf("period:     %s - %s\n", r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+	fmt.Printf("status:     %s\n", r.Status)
+	fmt.Printf("ticks:      %d\n", r.Ticks)
+
+	if r.Err != nil {
+		fmt.Printf("error:      %v\n", r.Err)
+	}
+
+	if r.Fill == nil {
+		fmt.Println("fill:       none")
+		return
+	}
+
+	fmt.Printf("fill:       %f at %s\n", r.Fill.Price, r.Fill.Time.Format(time.RFC3339))
+	fmt.Printf("plot price: %f\n", r.Fill.PlotPrice)
+	fmt.Printf("slippage:   %f\n", r.Fill.Slippage)
+	fmt.Printf("fee:        %f\n", r.Fill.Fee)
+	fmt.Printf("to fill:    %s\n", r.TimeToFill)
+	fmt.Printf("last price: %f\n", r.LastPrice)
+	fmt.Printf("PnL:        %f\n", r.PnL)
+}
@@ -1,20 +1,90 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"time"
+
 	"github.com/H3Cki/Plotor/controllers"
 	"github.com/H3Cki/Plotor/logger"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/H3Cki/Plotor/store"
 	"github.com/gin-gonic/gin"
 )
 
+// DATA_DIR_ENV is the name of the environment variable holding the directory where sessions and plot orders are persisted,
+// it is only used when STORE_KEY_ENV is set
+const DATA_DIR_ENV = "PLOTOR_DATA_DIR"
+
+// STORE_KEY_ENV is the name of the environment variable holding the base64 encoded 32 byte key exchange credentials
+// are encrypted with before they are persisted, sessions and plot orders are only persisted when it's set
+const STORE_KEY_ENV = "PLOTOR_STORE_KEY"
+
+// PAPER_FEED_DIR_ENV is the name of the environment variable holding the directory paper clients read their feed files from,
+// paper sessions can not be created when it's not set
+const PAPER_FEED_DIR_ENV = "PLOTOR_PAPER_FEED_DIR"
+
+// Environment variables configuring the scheduler that updates plot orders
+const (
+	WORKERS_ENV = "PLOTOR_WORKERS"
+	SPREAD_ENV  = "PLOTOR_SPREAD"
+	JITTER_ENV  = "PLOTOR_JITTER"
+)
+
 func main() {
+	plotor.SetDefaultScheduler(plotor.NewScheduler(plotor.RealClock{}, schedulerConfig()))
+
+	if secrets := storeCipher(); secrets != nil {
+		dataDir := os.Getenv(DATA_DIR_ENV)
+		if dataDir == "" {
+			dataDir = "data"
+		}
+
+		db, err := store.NewFileStore(dataDir)
+		if err != nil {
+			logger.Fatalf("error opening store: %v", err)
+		}
+
+		controllers.SetStore(db, secrets)
+	} else {
+		logger.Infof("persistence is disabled, set %s to persist sessions and plot orders", STORE_KEY_ENV)
+	}
+
+	controllers.SetPaperFeedDir(os.Getenv(PAPER_FEED_DIR_ENV))
+
+	// resume plot orders that were running before the restart
+	if err := controllers.Restore(context.Background()); err != nil {
+		logger.Errorf("error restoring plot orders: %v", err)
+	}
+
 	r := gin.Default()
 
 	// Managing plot orders
 	r.POST("/plotorder", controllers.CreatePlotOrder())
 	r.GET("/plotorder", controllers.GetPlotOrder())
+	r.PATCH("/plotorder", controllers.UpdatePlotOrder())
 	r.DELETE("/plotorder", controllers.CancelPlotOrder())
+	r.GET("/plotorders", controllers.ListPlotOrders())
+	r.POST("/plotorder/pause", controllers.PausePlotOrder())
+	r.POST("/plotorder/resume", controllers.ResumePlotOrder())
+	r.GET("/plotorder/history", controllers.PlotOrderHistory())
+	r.GET("/scheduler", controllers.SchedulerStats())
+	r.GET("/events", controllers.StreamEvents())
 	//r.POST("/attach", controllers.Attach())
 
+	// Managing brackets
+	r.POST("/bracket", controllers.CreateBracket())
+	r.GET("/bracket", controllers.GetBracket())
+	r.DELETE("/bracket", controllers.CancelBracket())
+
+	// Managing webhooks
+	r.POST("/webhook", controllers.CreateWebhook())
+	r.GET("/webhook", controllers.ListWebhooks())
+	r.DELETE("/webhook", controllers.DeleteWebhook())
+	r.GET("/webhook/deliveries", controllers.WebhookDeliveries())
+
 	// Managing Sessions
 	r.POST("/session", controllers.CreateSession())
 	r.GET("/session", controllers.GetSessions())
@@ -24,3 +94,55 @@ func main() {
 		logger.Error(err)
 	} // listen and serve on 0.0.0.0:8080
 }
+
+// storeCipher returns the cipher for persisted credentials, nil if persistence is not enabled
+func storeCipher() *store.Cipher {
+	v := os.Getenv(STORE_KEY_ENV)
+	if v == "" {
+		return nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		logger.Fatalf("error decoding %s: %v", STORE_KEY_ENV, err)
+	}
+
+	c, err := store.NewCipher(key)
+	if err != nil {
+		logger.Fatalf("error parsing %s: %v", STORE_KEY_ENV, err)
+	}
+
+	return c
+}
+
+func schedulerConfig() plotor.SchedulerConfig {
+	cfg := plotor.SchedulerConfig{}
+
+	if v := os.Getenv(WORKERS_ENV); v != "" {
+		workers, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Fatalf("error parsing %s: %v", WORKERS_ENV, err)
+		}
+
+		cfg.Workers = workers
+	}
+
+	cfg.Spread = durationEnv(SPREAD_ENV)
+	cfg.Jitter = durationEnv(JITTER_ENV)
+
+	return cfg
+}
+
+func durationEnv(name string) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		logger.Fatalf("error parsing %s: %v", name, err)
+	}
+
+	return d
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/gin-gonic/gin"
+)
+
+// createBracketRequest describes an entry plot order with take-profit and stop-loss plot orders placed once it's filled
+type createBracketRequest struct {
+	Interval   string
+	Entry      bracketLegRequest
+	TakeProfit bracketLegRequest
+	// StopLoss usually is a stop order e.g. STOP_LOSS or STOP_MARKET, the price of its plot is used as the stop price
+	StopLoss bracketLegRequest
+}
+
+type bracketLegRequest struct {
+	Plot  json.RawMessage
+	Order json.RawMessage
+	plotOrderOptions
+}
+
+func (lr bracketLegRequest) leg() (plotor.BracketLeg, error) {
+	plot, err := geometry.FromJSON(lr.Plot)
+	if err != nil {
+		return plotor.BracketLeg{}, fmt.Errorf("error parsing plot: %w", err)
+	}
+
+	opts, err := lr.options()
+	if err != nil {
+		return plotor.BracketLeg{}, fmt.Errorf("error parsing options: %w", err)
+	}
+
+	return plotor.BracketLeg{OrderData: lr.Order, Plot: plot, Options: opts}, nil
+}
+
+type bracketResponse struct {
+	BracketID    string
+	Status       plotor.BracketStatus
+	StatusError  string
+	EntryID      string
+	TakeProfitID string
+	StopLossID   string
+	Interval     string
+	Error        string
+}
+
+func newBracketResponse(b *plotor.Bracket) bracketResponse {
+	statusErr := ""
+	if b.Err != nil {
+		statusErr = b.Err.Error()
+	}
+
+	return bracketResponse{
+		BracketID:    b.ID,
+		Status:       b.Status,
+		StatusError:  statusErr,
+		EntryID:      b.EntryID,
+		TakeProfitID: b.TakeProfitID,
+		StopLossID:   b.StopLossID,
+		Interval:     b.Interval.String(),
+	}
+}
+
+func bracketErr(prefix string, err error) bracketResponse {
+	if err != nil {
+		return bracketResponse{Error: fmt.Sprintf("%s: %s", prefix, err.Error())}
+	}
+
+	return bracketResponse{Error: prefix}
+}
+
+func CreateBracket() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("session does not exist", nil))
+			return
+		}
+
+		cbr := createBracketRequest{}
+		if err := c.BindJSON(&cbr); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("error marshalling request body", err))
+			return
+		}
+
+		itv, err := plotor.ParseInterval(cbr.Interval)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("error parsing interval", err))
+			return
+		}
+
+		entry, err := cbr.Entry.leg()
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("error parsing entry", err))
+			return
+		}
+
+		takeProfit, err := cbr.TakeProfit.leg()
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("error parsing take-profit", err))
+			return
+		}
+
+		stopLoss, err := cbr.StopLoss.leg()
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("error parsing stop-loss", err))
+			return
+		}
+
+		b, err := session.PlotOrderer.CreateBracket(context.Background(), entry, takeProfit, stopLoss, itv)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("error creating bracket", err))
+			return
+		}
+
+		if err := persistBracket(session, b, cbr); err != nil {
+			res := bracketErr("error persisting bracket", err)
+			res.BracketID = b.ID
+			c.IndentedJSON(http.StatusInternalServerError, res)
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, newBracketResponse(b))
+	}
+}
+
+func GetBracket() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("session does not exist", nil))
+			return
+		}
+
+		bracketID := c.Query("id")
+		if bracketID == "" {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("id can not be empty", nil))
+			return
+		}
+
+		b, err := session.PlotOrderer.Bracket(bracketID)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, bracketErr("error getting bracket", err))
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, newBracketResponse(b))
+	}
+}
+
+// CancelBracket stops the plot orders of the bracket, their orders are cancelled when cancel query param is true
+func CancelBracket() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		bracketID := c.Query("id")
+		if bracketID == "" {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("id can not be empty")))
+			return
+		}
+
+		cancel := false
+		if cancelParam := c.Query("cancel"); cancelParam != "" {
+			cancelValue, err := strconv.ParseBool(cancelParam)
+			if err != nil {
+				c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("invalid cancel param: %v", err)))
+				return
+			}
+
+			cancel = cancelValue
+		}
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("session does not exist")))
+			return
+		}
+
+		if err := session.PlotOrderer.StopBracket(context.Background(), bracketID, cancel); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("error stopping bracket: %v", err)))
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, errResponse{})
+	}
+}
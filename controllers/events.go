@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamEvents streams events of plot orders of the session as server-sent events named after the event type,
+// the stream can be narrowed down to a single plot order using the id query param
+func StreamEvents() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("session does not exist")))
+			return
+		}
+
+		plotOrderID := c.Query("id")
+
+		events, unsubscribe := session.PlotOrderer.Subscribe(0)
+		defer unsubscribe()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case e, ok := <-events:
+				if !ok {
+					return false
+				}
+
+				if plotOrderID == "" || e.PlotOrderID == plotOrderID {
+					c.SSEvent(string(e.Type), e)
+				}
+
+				return true
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/gin-gonic/gin"
+)
+
+type plotOrderHistoryResponse struct {
+	PlotOrderID string
+	History     plotor.History
+	Error       string
+}
+
+// PlotOrderHistory returns the recorded ticks of a plot order from the oldest to the most recent one,
+// they can be filtered using since (RFC3339), errors and limit query params e.g. ?id=...&errors=true&limit=10
+func PlotOrderHistory() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, plotOrderHistoryResponse{Error: "session does not exist"})
+			return
+		}
+
+		plotOrderID := c.Query("id")
+		if plotOrderID == "" {
+			c.IndentedJSON(http.StatusBadRequest, plotOrderHistoryResponse{Error: "id can not be empty"})
+			return
+		}
+
+		filter, err := historyFilter(c)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, plotOrderHistoryResponse{Error: err.Error()})
+			return
+		}
+
+		history, err := session.PlotOrderer.History(plotOrderID, filter)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, plotOrderHistoryResponse{Error: fmt.Sprintf("error getting history: %s", err.Error())})
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, plotOrderHistoryResponse{PlotOrderID: plotOrderID, History: history})
+	}
+}
+
+func historyFilter(c *gin.Context) (plotor.HistoryFilter, error) {
+	filter := plotor.HistoryFilter{}
+
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since param: %w", err)
+		}
+
+		filter.Since = t
+	}
+
+	if errorsParam := c.Query("errors"); errorsParam != "" {
+		errorsOnly, err := strconv.ParseBool(errorsParam)
+		if err != nil {
+			return filter, fmt.Errorf("invalid errors param: %w", err)
+		}
+
+		filter.Errors = errorsOnly
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return filter, fmt.Errorf("invalid limit param: %w", err)
+		}
+
+		filter.Limit = n
+	}
+
+	return filter, nil
+}
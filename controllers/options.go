@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/H3Cki/Plotor/plotor"
+)
+
+// plotOrderOptions holds optional settings of a plot order, they are persisted with the plot order and applied again when it's restored
+type plotOrderOptions struct {
+	Retry *retryPolicy `json:",omitempty"`
+	// OutOfRange is one of LEAVE, CANCEL, MARKET or WAIT
+	OutOfRange string `json:",omitempty"`
+	// UpdatePolicy sets thresholds below which the order is not re-priced
+	UpdatePolicy *plotor.UpdatePolicy `json:",omitempty"`
+	// HistorySize is the number of tick records kept, plotor.DEFAULT_HISTORY_SIZE is used when it's zero
+	HistorySize int `json:",omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff string
+	MaxBackoff     string
+	Multiplier     float64
+}
+
+// options converts the settings to plotor options, unset settings keep plotor defaults
+func (o plotOrderOptions) options() ([]plotor.Option, error) {
+	opts := []plotor.Option{}
+
+	if o.Retry != nil {
+		policy, err := o.Retry.policy()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing retry policy: %w", err)
+		}
+
+		opts = append(opts, plotor.WithRetry(policy))
+	}
+
+	if o.OutOfRange != "" {
+		policy := plotor.OutOfRangePolicy(strings.ToUpper(o.OutOfRange))
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, plotor.WithOutOfRange(policy))
+	}
+
+	if o.UpdatePolicy != nil {
+		if err := o.UpdatePolicy.Validate(); err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, plotor.WithUpdatePolicy(*o.UpdatePolicy))
+	}
+
+	if o.HistorySize != 0 {
+		opts = append(opts, plotor.WithHistorySize(o.HistorySize))
+	}
+
+	return opts, nil
+}
+
+func (rp retryPolicy) policy() (plotor.RetryPolicy, error) {
+	policy := plotor.RetryPolicy{MaxAttempts: rp.MaxAttempts, Multiplier: rp.Multiplier}
+
+	var err error
+
+	if policy.InitialBackoff, err = parseOptionalDuration(rp.InitialBackoff); err != nil {
+		return plotor.RetryPolicy{}, fmt.Errorf("error parsing initial backoff: %w", err)
+	}
+
+	if policy.MaxBackoff, err = parseOptionalDuration(rp.MaxBackoff); err != nil {
+		return plotor.RetryPolicy{}, fmt.Errorf("error parsing max backoff: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return plotor.RetryPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	return time.ParseDuration(s)
+}
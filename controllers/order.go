@@ -18,21 +18,132 @@ type createPlotOrderRequest struct {
 	Interval string
 	Plot     json.RawMessage
 	Order    json.RawMessage
+	// Alert creates an alert instead of an order when it's set, Order is ignored
+	Alert *alertRequest
+	// Trigger delays placing the order until the market price crosses the trigger plot, it can not be set with Alert
+	Trigger *triggerRequest
+	plotOrderOptions
+}
+
+type alertRequest struct {
+	Symbol string
+}
+
+type triggerRequest struct {
+	Symbol string
+	Plot   json.RawMessage
+	// Direction is ABOVE or BELOW
+	Direction string
+}
+
+func (tr triggerRequest) option() (plotor.Option, error) {
+	plot, err := geometry.FromJSON(tr.Plot)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing trigger plot: %w", err)
+	}
+
+	return plotor.WithTrigger(tr.Symbol, plot, plotor.AlertSide(strings.ToUpper(tr.Direction))), nil
 }
 
 type createPlotOrderResponse struct {
 	PlotOrderID string
 	ClientOrder any
+	Alert       *plotor.Alert `json:",omitempty"`
 	Error       string
 }
 
 type getPlotOrderResponse struct {
 	PlotOrderID string
+	Status      plotor.Status
+	StatusError string
 	ClientOrder map[string]any
-	Plot        map[string]any
+	Alert       *plotor.Alert    `json:",omitempty"`
+	Trigger     *triggerResponse `json:",omitempty"`
+	Plot        json.RawMessage
 	Interval    string
 	LastTick    time.Time
-	Error       string
+	// SkippedTicks is the number of ticks at which the order was not re-priced because of the update policy
+	SkippedTicks int
+	Error        string
+}
+
+// orderDetails returns the details of the order of the plot order, alerts have none
+func orderDetails(po *plotor.PlotOrder) (map[string]any, error) {
+	if po.Order == nil {
+		return nil, nil
+	}
+
+	return po.Order.Details()
+}
+
+type triggerResponse struct {
+	Symbol      string
+	Plot        json.RawMessage
+	Direction   plotor.AlertSide
+	Side        plotor.AlertSide
+	MarketPrice float64
+	// Triggered is the time the order was placed, null while the plot order waits for the trigger
+	Triggered *time.Time
+}
+
+func newTriggerResponse(t *plotor.Trigger) (*triggerResponse, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	plot, err := geometry.ToJSON(t.Plot)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing trigger plot: %w", err)
+	}
+
+	res := &triggerResponse{
+		Symbol:      t.Symbol,
+		Plot:        plot,
+		Direction:   t.Direction,
+		Side:        t.Side,
+		MarketPrice: t.MarketPrice,
+	}
+
+	if !t.Triggered.IsZero() {
+		res.Triggered = &t.Triggered
+	}
+
+	return res, nil
+}
+
+func newGetPlotOrderResponse(po *plotor.PlotOrder) (getPlotOrderResponse, error) {
+	details, err := orderDetails(po)
+	if err != nil {
+		return getPlotOrderResponse{}, fmt.Errorf("error retrieving order details: %w", err)
+	}
+
+	plot, err := geometry.ToJSON(po.Plot)
+	if err != nil {
+		return getPlotOrderResponse{}, fmt.Errorf("error serializing plot: %w", err)
+	}
+
+	trigger, err := newTriggerResponse(po.Trigger)
+	if err != nil {
+		return getPlotOrderResponse{}, err
+	}
+
+	statusErr := ""
+	if po.Err != nil {
+		statusErr = po.Err.Error()
+	}
+
+	return getPlotOrderResponse{
+		PlotOrderID:  po.ID,
+		Status:       po.Status,
+		StatusError:  statusErr,
+		Interval:     po.Interval.String(),
+		ClientOrder:  details,
+		Alert:        po.Alert,
+		Trigger:      trigger,
+		Plot:         plot,
+		LastTick:     po.LastTick,
+		SkippedTicks: po.SkippedTicks,
+	}, nil
 }
 
 func gpoErr(prefix string, err error) getPlotOrderResponse {
@@ -95,13 +206,47 @@ func CreatePlotOrder() func(c *gin.Context) {
 			return
 		}
 
-		po, err := session.PlotOrderer.Create(context.Background(), cpor.Order, plot, itv)
+		opts, err := cpor.options()
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, cpoErr("error parsing options", err))
+			return
+		}
+
+		if cpor.Alert != nil && cpor.Trigger != nil {
+			c.IndentedJSON(http.StatusBadRequest, cpoErr("alerts can not have a trigger", nil))
+			return
+		}
+
+		if cpor.Trigger != nil {
+			opt, err := cpor.Trigger.option()
+			if err != nil {
+				c.IndentedJSON(http.StatusBadRequest, cpoErr("error parsing trigger", err))
+				return
+			}
+
+			opts = append(opts, opt)
+		}
+
+		var po *plotor.PlotOrder
+		if cpor.Alert != nil {
+			po, err = session.PlotOrderer.CreateAlert(context.Background(), cpor.Alert.Symbol, plot, itv, opts...)
+		} else {
+			po, err = session.PlotOrderer.Create(context.Background(), cpor.Order, plot, itv, opts...)
+		}
+
 		if err != nil {
 			c.IndentedJSON(http.StatusBadRequest, cpoErr("error creating order", err))
 			return
 		}
 
-		details, err := po.Order.Details()
+		if err := persistPlotOrder(session, po, cpor.plotOrderOptions); err != nil {
+			res := cpoErr("error persisting plot order", err)
+			res.PlotOrderID = po.ID
+			c.IndentedJSON(http.StatusInternalServerError, res)
+			return
+		}
+
+		details, err := orderDetails(po)
 		if err != nil {
 			res := cpoErr("error retrieving order details", err)
 			res.PlotOrderID = po.ID
@@ -112,6 +257,7 @@ func CreatePlotOrder() func(c *gin.Context) {
 		c.IndentedJSON(http.StatusOK, createPlotOrderResponse{
 			PlotOrderID: po.ID,
 			ClientOrder: details,
+			Alert:       po.Alert,
 		})
 	}
 }
@@ -138,21 +284,143 @@ func GetPlotOrder() func(c *gin.Context) {
 			return
 		}
 
-		details, err := po.Order.Details()
+		res, err := newGetPlotOrderResponse(po)
 		if err != nil {
-			c.IndentedJSON(http.StatusInternalServerError, gpoErr("error retrieving order details", err))
+			c.IndentedJSON(http.StatusInternalServerError, gpoErr("error building response", err))
 			return
 		}
 
-		c.IndentedJSON(http.StatusOK, getPlotOrderResponse{
-			PlotOrderID: po.ID,
-			Interval:    po.Interval.String(),
-			ClientOrder: details,
-			Plot: map[string]interface{}{
-				"plot json will be here": nil,
-			},
-			LastTick: po.LastTick,
-		})
+		c.IndentedJSON(http.StatusOK, res)
+	}
+}
+
+type listPlotOrdersItem struct {
+	PlotOrderID string
+	Status      plotor.Status
+	Interval    string
+	LastTick    time.Time
+	// PlotPrice is the current price of the plot, it's null when the plot is out of range
+	PlotPrice   *float64
+	ClientOrder map[string]any
+	Alert       *plotor.Alert `json:",omitempty"`
+}
+
+type listPlotOrdersResponse struct {
+	PlotOrders []listPlotOrdersItem
+	Error      string
+}
+
+// ListPlotOrders returns all plot orders of the session, they can be filtered using
+// comma separated status and symbol query params e.g. ?status=RUNNING,PAUSED&symbol=BTCUSDT
+func ListPlotOrders() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, listPlotOrdersResponse{Error: "session does not exist"})
+			return
+		}
+
+		filter := plotor.ListFilter{Symbol: c.Query("symbol")}
+		if statusParam := c.Query("status"); statusParam != "" {
+			for _, status := range strings.Split(statusParam, ",") {
+				filter.Statuses = append(filter.Statuses, plotor.Status(strings.ToUpper(strings.TrimSpace(status))))
+			}
+		}
+
+		now := session.PlotOrderer.Now()
+		items := []listPlotOrdersItem{}
+
+		for _, po := range session.PlotOrderer.List(filter) {
+			details, err := orderDetails(po)
+			if err != nil {
+				c.IndentedJSON(http.StatusInternalServerError, listPlotOrdersResponse{Error: fmt.Sprintf("error retrieving order details: %s", err.Error())})
+				return
+			}
+
+			item := listPlotOrdersItem{
+				PlotOrderID: po.ID,
+				Status:      po.Status,
+				Interval:    po.Interval.String(),
+				LastTick:    po.LastTick,
+				ClientOrder: details,
+				Alert:       po.Alert,
+			}
+
+			if price, err := po.Plot.At(now); err == nil {
+				item.PlotPrice = &price
+			}
+
+			items = append(items, item)
+		}
+
+		c.IndentedJSON(http.StatusOK, listPlotOrdersResponse{PlotOrders: items})
+	}
+}
+
+type updatePlotOrderRequest struct {
+	Interval string
+	Plot     json.RawMessage
+}
+
+func UpdatePlotOrder() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, gpoErr("session does not exist", nil))
+			return
+		}
+
+		plotOrderID := c.Query("id")
+		if plotOrderID == "" {
+			c.IndentedJSON(http.StatusBadRequest, gpoErr("id can not be empty", nil))
+			return
+		}
+
+		upor := updatePlotOrderRequest{}
+		if err := c.BindJSON(&upor); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gpoErr("error marshalling request body", err))
+			return
+		}
+
+		var itv time.Duration
+		if upor.Interval != "" {
+			parsed, err := plotor.ParseInterval(upor.Interval)
+			if err != nil {
+				c.IndentedJSON(http.StatusBadRequest, gpoErr("error parsing interval", err))
+				return
+			}
+
+			itv = parsed
+		}
+
+		var plot geometry.Plot
+		if len(upor.Plot) > 0 && string(upor.Plot) != "null" {
+			parsed, err := geometry.FromJSON(upor.Plot)
+			if err != nil {
+				c.IndentedJSON(http.StatusBadRequest, gpoErr("error parsing plot", err))
+				return
+			}
+
+			plot = parsed
+		}
+
+		po, err := session.PlotOrderer.Update(context.Background(), plotOrderID, plot, itv)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gpoErr("error updating plot order", err))
+			return
+		}
+
+		res, err := newGetPlotOrderResponse(po)
+		if err != nil {
+			c.IndentedJSON(http.StatusInternalServerError, gpoErr("error building response", err))
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, res)
 	}
 }
 
@@ -190,6 +458,61 @@ func CancelPlotOrder() func(c *gin.Context) {
 			return
 		}
 
+		if err := forgetPlotOrder(plotOrderID); err != nil {
+			c.IndentedJSON(http.StatusInternalServerError, newErrResponse(fmt.Errorf("error removing persisted order: %v", err)))
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, errResponse{})
+	}
+}
+
+func PausePlotOrder() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		plotOrderID := c.Query("id")
+		if plotOrderID == "" {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("id can not be empty")))
+			return
+		}
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("session does not exist")))
+			return
+		}
+
+		if err := session.PlotOrderer.Pause(plotOrderID); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("error pausing order: %v", err)))
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, errResponse{})
+	}
+}
+
+func ResumePlotOrder() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		plotOrderID := c.Query("id")
+		if plotOrderID == "" {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("id can not be empty")))
+			return
+		}
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("session does not exist")))
+			return
+		}
+
+		if err := session.PlotOrderer.Resume(context.Background(), plotOrderID); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("error resuming order: %v", err)))
+			return
+		}
+
 		c.IndentedJSON(http.StatusOK, errResponse{})
 	}
 }
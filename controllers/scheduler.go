@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/gin-gonic/gin"
+)
+
+type schedulerStatsResponse struct {
+	plotor.SchedulerStats
+}
+
+// SchedulerStats reports the queue depth of the scheduler shared by all sessions, it requires a valid session
+func SchedulerStats() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		if _, ok := sessions.get(auth); !ok {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("session does not exist")))
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, schedulerStatsResponse{plotor.DefaultScheduler().Stats()})
+	}
+}
@@ -4,17 +4,25 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/H3Cki/Plotor/clients/binance"
+	"github.com/H3Cki/Plotor/clients/paper"
+	"github.com/H3Cki/Plotor/logger"
 	"github.com/H3Cki/Plotor/plotor"
+	"github.com/H3Cki/Plotor/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// WEBHOOK_TIMEOUT is the timeout of a single webhook request
+const WEBHOOK_TIMEOUT = 10 * time.Second
+
 var sessions = &sessionRegistry{
 	sessions: map[string]*session{},
 	mu:       &sync.Mutex{},
@@ -31,17 +39,17 @@ func (ss *sessionRegistry) add(s *session) {
 	ss.sessions[s.Token()] = s
 }
 
-func (ss *sessionRegistry) delete(token string) error {
+func (ss *sessionRegistry) delete(token string) (*session, error) {
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
-	_, ok := ss.sessions[token]
+	s, ok := ss.sessions[token]
 	if !ok {
-		return fmt.Errorf("session does not exist")
+		return nil, fmt.Errorf("session does not exist")
 	}
 
 	delete(ss.sessions, token)
 
-	return nil
+	return s, nil
 }
 
 func (ss *sessionRegistry) get(token string) (*session, bool) {
@@ -77,15 +85,38 @@ func (ss *sessionRegistry) hash(hash []byte) []*session {
 type session struct {
 	token       string
 	hash        []byte
+	client      string
+	auth        json.RawMessage
 	PlotOrderer *plotor.PlotOrderer
+	// webhooks are notified about events of the plot orders of the session
+	webhooks    *webhook.Dispatcher
+	unsubscribe func()
 }
 
-func newSession(hash []byte, po *plotor.PlotOrderer) *session {
-	return &session{
+func newSession(hash []byte, client string, auth json.RawMessage, po *plotor.PlotOrderer) *session {
+	s := &session{
 		hash:        hash,
 		token:       uuid.NewString(),
+		client:      client,
+		auth:        auth,
 		PlotOrderer: po,
+		webhooks:    webhook.NewDispatcher(webhook.NewHTTPClient(WEBHOOK_TIMEOUT), webhook.DefaultRetryPolicy),
 	}
+
+	po.OnUpdate(s.persistUpdate)
+	po.OnBracketUpdate(s.persistBracketUpdate)
+
+	events, unsubscribe := po.Subscribe(0)
+	s.unsubscribe = unsubscribe
+	go s.webhooks.Run(events)
+
+	return s
+}
+
+// close stops notifying the webhooks of the session
+func (s *session) close() {
+	s.unsubscribe()
+	s.webhooks.Close()
 }
 
 func (s *session) Token() string {
@@ -127,12 +158,17 @@ func CreateSession() func(c *gin.Context) {
 			return
 		}
 
-		session := newSession(hash, plotor.NewPlotOrderer(exchange))
+		session := newSession(hash, ea.Client, ea.Auth, plotor.NewPlotOrderer(exchange))
 		if _, ok := sessions.get(session.Token()); ok {
 			c.IndentedJSON(http.StatusInternalServerError, createSessionResponse{Error: "error creating session: duplicate token"})
 			return
 		}
 
+		if err := persistSession(session); err != nil {
+			c.IndentedJSON(http.StatusInternalServerError, createSessionResponse{Error: err.Error()})
+			return
+		}
+
 		sessions.add(session)
 
 		c.IndentedJSON(http.StatusOK, createSessionResponse{
@@ -170,6 +206,14 @@ func GetSessions() func(c *gin.Context) {
 	}
 }
 
+// paperFeedDir is the directory feed files of paper clients are read from, paper clients are disabled when it's empty
+var paperFeedDir string
+
+// SetPaperFeedDir enables paper clients, the feed files they are given are resolved against dir
+func SetPaperFeedDir(dir string) {
+	paperFeedDir = dir
+}
+
 func client(name string, auth []byte) (plotor.Client, error) {
 	switch name {
 	case "BINANCE_SPOT":
@@ -199,12 +243,63 @@ func client(name string, auth []byte) (plotor.Client, error) {
 			return nil, fmt.Errorf("error setting up client: %w", err)
 		}
 
+		return client, nil
+	case "PAPER":
+		if paperFeedDir == "" {
+			return nil, errors.New("paper trading is disabled, the server has no feed directory")
+		}
+
+		cfg := paper.Config{}
+
+		if err := json.Unmarshal(auth, &cfg); err != nil {
+			return nil, fmt.Errorf("error unmarshalling paper config: %w", err)
+		}
+
+		// feed files come from the request, they must not leave the feed directory
+		cfg.FeedDir = paperFeedDir
+
+		client := &paper.Client{}
+
+		if err := client.SetUp(cfg); err != nil {
+			// the error of a feed can quote the content of the file
+			var feedErr *paper.FeedError
+			if errors.As(err, &feedErr) {
+				logger.Errorf("error setting up paper client: %v", err)
+				return nil, fmt.Errorf("error setting up client: error loading feed for symbol %s", feedErr.Symbol)
+			}
+
+			return nil, fmt.Errorf("error setting up client: %w", err)
+		}
+
 		return client, nil
 	}
 
 	return nil, fmt.Errorf("unsupported client: %s", name)
 }
 
+// clientOrder unmarshals an order previously marshalled from the given client's ClientOrder
+func clientOrder(name string, data []byte) (plotor.ClientOrder, error) {
+	var order plotor.ClientOrder
+
+	switch name {
+	case "BINANCE_SPOT":
+		order = &binance.SpotOrder{}
+	case "BINANCE_FUTURES":
+		order = &binance.FuturesOrder{}
+	case "PAPER":
+		// paper orders live in memory only, restoring them fails once the process restarts
+		order = &paper.Order{}
+	default:
+		return nil, fmt.Errorf("unsupported client: %s", name)
+	}
+
+	if err := json.Unmarshal(data, order); err != nil {
+		return nil, fmt.Errorf("error unmarshalling order: %w", err)
+	}
+
+	return order, nil
+}
+
 type deleteSessionResponse struct {
 	Error string
 }
@@ -213,11 +308,19 @@ func DeleteSession() func(c *gin.Context) {
 	return func(c *gin.Context) {
 		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
 
-		if err := sessions.delete(token); err != nil {
+		s, err := sessions.delete(token)
+		if err != nil {
 			c.IndentedJSON(http.StatusBadRequest, createSessionResponse{Error: err.Error()})
 			return
 		}
 
+		s.close()
+
+		if err := forgetSession(token); err != nil {
+			c.IndentedJSON(http.StatusInternalServerError, deleteSessionResponse{Error: err.Error()})
+			return
+		}
+
 		c.IndentedJSON(http.StatusOK, deleteSessionResponse{})
 	}
 }
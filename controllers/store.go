@@ -0,0 +1,683 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/logger"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/H3Cki/Plotor/store"
+	"github.com/H3Cki/Plotor/webhook"
+)
+
+// db persists sessions and plot orders, persistence is disabled when it's nil
+var db store.Store
+
+// secrets encrypts the credentials and webhooks of sessions before they are persisted
+var secrets *store.Cipher
+
+// persistMu guards read-modify-write cycles on persisted plot orders
+var persistMu = &sync.Mutex{}
+
+// SetStore enables persistence of sessions and plot orders using given store. Sessions are persisted with their exchange
+// credentials and webhooks so they can be restored, both are encrypted with c and the store is not used when c is nil.
+func SetStore(s store.Store, c *store.Cipher) {
+	if c == nil {
+		return
+	}
+
+	db, secrets = s, c
+}
+
+// Restore recreates persisted sessions and resumes their plot orders,
+// records that can not be restored are logged and skipped
+func Restore(ctx context.Context) error {
+	if db == nil {
+		return nil
+	}
+
+	sessionRecords, err := db.Sessions()
+	if err != nil {
+		return fmt.Errorf("error loading sessions: %w", err)
+	}
+
+	for _, rec := range sessionRecords {
+		auth, err := secrets.Open(rec.Auth)
+		if err != nil {
+			logger.Errorf("error restoring session: error decrypting credentials: %v", err)
+			continue
+		}
+
+		exchange, err := client(rec.Client, auth)
+		if err != nil {
+			logger.Errorf("error restoring session: %v", err)
+			continue
+		}
+
+		s := newSession(rec.Hash, rec.Client, auth, plotor.NewPlotOrderer(exchange))
+		s.token = rec.Token
+
+		if err := restoreWebhooks(s, rec.Webhooks); err != nil {
+			logger.Errorf("error restoring webhooks of session: %v", err)
+		}
+
+		sessions.add(s)
+	}
+
+	plotOrderRecords, err := db.PlotOrders()
+	if err != nil {
+		return fmt.Errorf("error loading plot orders: %w", err)
+	}
+
+	for _, rec := range plotOrderRecords {
+		if err := restorePlotOrder(ctx, rec); err != nil {
+			logger.Errorf("error restoring plot order %s: %v", rec.ID, err)
+			continue
+		}
+
+		logger.Infof("restored plot order %s", rec.ID)
+	}
+
+	// brackets are linked to the plot orders of their legs so they are restored last
+	bracketRecords, err := db.Brackets()
+	if err != nil {
+		return fmt.Errorf("error loading brackets: %w", err)
+	}
+
+	for _, rec := range bracketRecords {
+		if err := restoreBracket(ctx, rec); err != nil {
+			logger.Errorf("error restoring bracket %s: %v", rec.ID, err)
+			continue
+		}
+
+		logger.Infof("restored bracket %s", rec.ID)
+	}
+
+	return nil
+}
+
+func restoreBracket(ctx context.Context, rec store.Bracket) error {
+	s, ok := sessions.get(rec.SessionToken)
+	if !ok {
+		return errors.New("session does not exist")
+	}
+
+	takeProfit, err := unmarshalLeg(rec.TakeProfit)
+	if err != nil {
+		return fmt.Errorf("error restoring take-profit: %w", err)
+	}
+
+	stopLoss, err := unmarshalLeg(rec.StopLoss)
+	if err != nil {
+		return fmt.Errorf("error restoring stop-loss: %w", err)
+	}
+
+	itv, err := plotor.ParseInterval(rec.Interval)
+	if err != nil {
+		return fmt.Errorf("error parsing interval: %w", err)
+	}
+
+	b := &plotor.Bracket{
+		ID:           rec.ID,
+		Status:       plotor.BracketStatus(rec.Status),
+		EntryID:      rec.EntryID,
+		TakeProfitID: rec.TakeProfitID,
+		StopLossID:   rec.StopLossID,
+		Interval:     itv,
+
+		EntryPartiallyFilled: rec.EntryPartiallyFilled,
+	}
+
+	if rec.Error != "" {
+		b.Err = errors.New(rec.Error)
+	}
+
+	_, err = s.PlotOrderer.RestoreBracket(ctx, b, takeProfit, stopLoss)
+
+	return err
+}
+
+func unmarshalLeg(data json.RawMessage) (plotor.BracketLeg, error) {
+	lr := bracketLegRequest{}
+	if err := json.Unmarshal(data, &lr); err != nil {
+		return plotor.BracketLeg{}, fmt.Errorf("error unmarshalling leg: %w", err)
+	}
+
+	return lr.leg()
+}
+
+func restorePlotOrder(ctx context.Context, rec store.PlotOrder) error {
+	s, ok := sessions.get(rec.SessionToken)
+	if !ok {
+		return errors.New("session does not exist")
+	}
+
+	var alert *plotor.Alert
+	if len(rec.Alert) > 0 {
+		alert = &plotor.Alert{}
+		if err := json.Unmarshal(rec.Alert, alert); err != nil {
+			return fmt.Errorf("error unmarshalling alert: %w", err)
+		}
+	}
+
+	trigger, err := unmarshalTrigger(rec.Trigger)
+	if err != nil {
+		return err
+	}
+
+	// alerts and plot orders waiting for their trigger have no order
+	var order plotor.ClientOrder
+	if alert == nil && (trigger == nil || !trigger.Triggered.IsZero()) {
+		order, err = clientOrder(rec.Client, rec.Order)
+		if err != nil {
+			return err
+		}
+	}
+
+	plot, err := geometry.FromJSON(rec.Plot)
+	if err != nil {
+		return fmt.Errorf("error parsing plot: %w", err)
+	}
+
+	itv, err := plotor.ParseInterval(rec.Interval)
+	if err != nil {
+		return fmt.Errorf("error parsing interval: %w", err)
+	}
+
+	options := plotOrderOptions{}
+	if len(rec.Options) > 0 {
+		if err := json.Unmarshal(rec.Options, &options); err != nil {
+			return fmt.Errorf("error unmarshalling options: %w", err)
+		}
+	}
+
+	opts, err := options.options()
+	if err != nil {
+		return err
+	}
+
+	po := plotor.NewPlotOrder(order, plot, itv, opts...)
+	po.ID = rec.ID
+	po.Alert = alert
+	po.Trigger = trigger
+	po.LastTick = rec.LastTick
+	if plotor.Status(rec.Status) == plotor.StatusPaused {
+		po.Status = plotor.StatusPaused
+	}
+
+	if len(rec.History) > 0 {
+		if err := json.Unmarshal(rec.History, &po.History); err != nil {
+			return fmt.Errorf("error unmarshalling history: %w", err)
+		}
+	}
+
+	_, err = s.PlotOrderer.Restore(ctx, po)
+
+	return err
+}
+
+func restoreWebhooks(s *session, sealed []byte) error {
+	if len(sealed) == 0 {
+		return nil
+	}
+
+	data, err := secrets.Open(sealed)
+	if err != nil {
+		return fmt.Errorf("error decrypting webhooks: %w", err)
+	}
+
+	webhooks := []webhook.Webhook{}
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return fmt.Errorf("error unmarshalling webhooks: %w", err)
+	}
+
+	for _, w := range webhooks {
+		if _, err := s.webhooks.Add(w); err != nil {
+			return fmt.Errorf("error adding webhook %s: %w", w.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func persistSession(s *session) error {
+	if db == nil {
+		return nil
+	}
+
+	var webhooks []byte
+
+	// the webhooks are encrypted as a whole, they contain their secrets
+	if registered := s.webhooks.Webhooks(); len(registered) > 0 {
+		data, err := json.Marshal(registered)
+		if err != nil {
+			return fmt.Errorf("error marshalling webhooks: %w", err)
+		}
+
+		webhooks, err = secrets.Seal(data)
+		if err != nil {
+			return fmt.Errorf("error encrypting webhooks: %w", err)
+		}
+	}
+
+	auth, err := secrets.Seal(s.auth)
+	if err != nil {
+		return fmt.Errorf("error encrypting credentials: %w", err)
+	}
+
+	return db.SaveSession(store.Session{
+		Token:    s.Token(),
+		Hash:     s.Hash(),
+		Client:   s.client,
+		Auth:     auth,
+		Webhooks: webhooks,
+	})
+}
+
+// forgetSession removes the session and all of its plot orders and brackets from the store
+func forgetSession(token string) error {
+	if db == nil {
+		return nil
+	}
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	if err := db.DeleteSession(token); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	records, err := db.PlotOrders()
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if rec.SessionToken != token {
+			continue
+		}
+
+		if err := db.DeletePlotOrder(rec.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+
+	brackets, err := db.Brackets()
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range brackets {
+		if rec.SessionToken != token {
+			continue
+		}
+
+		if err := db.DeleteBracket(rec.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func persistPlotOrder(s *session, po *plotor.PlotOrder, options plotOrderOptions) error {
+	if db == nil {
+		return nil
+	}
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	return savePlotOrder(s, po, options)
+}
+
+// savePlotOrder creates the record of a plot order, persistMu has to be locked
+func savePlotOrder(s *session, po *plotor.PlotOrder, options plotOrderOptions) error {
+	plot, err := geometry.ToJSON(po.Plot)
+	if err != nil {
+		return fmt.Errorf("error serializing plot: %w", err)
+	}
+
+	order, err := json.Marshal(po.Order)
+	if err != nil {
+		return fmt.Errorf("error marshalling order: %w", err)
+	}
+
+	opts, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("error marshalling options: %w", err)
+	}
+
+	alert, err := marshalAlert(po.Alert)
+	if err != nil {
+		return err
+	}
+
+	trigger, err := marshalTrigger(po.Trigger)
+	if err != nil {
+		return err
+	}
+
+	return db.SavePlotOrder(store.PlotOrder{
+		ID:           po.ID,
+		SessionToken: s.Token(),
+		Client:       s.client,
+		Plot:         plot,
+		Interval:     po.Interval.String(),
+		Status:       string(po.Status),
+		Order:        order,
+		Alert:        alert,
+		Trigger:      trigger,
+		LastTick:     po.LastTick,
+		Options:      opts,
+	})
+}
+
+func forgetPlotOrder(plotOrderID string) error {
+	if db == nil {
+		return nil
+	}
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	if err := db.DeletePlotOrder(plotOrderID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// persistUpdate updates the state of a persisted plot order,
+// plot orders that reached a terminal status are removed and plot orders that were not persisted yet are skipped
+func (s *session) persistUpdate(po *plotor.PlotOrder) {
+	if db == nil {
+		return
+	}
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	if po.Status.Terminal() {
+		if err := db.DeletePlotOrder(po.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			logger.Errorf("error removing persisted plot order %s: %v", po.ID, err)
+		}
+
+		return
+	}
+
+	rec, err := db.PlotOrder(po.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return
+	}
+
+	if err != nil {
+		logger.Errorf("error loading persisted plot order %s: %v", po.ID, err)
+		return
+	}
+
+	order, err := json.Marshal(po.Order)
+	if err != nil {
+		logger.Errorf("error marshalling order of plot order %s: %v", po.ID, err)
+		return
+	}
+
+	// the plot and the interval can be replaced while the plot order is running
+	plot, err := geometry.ToJSON(po.Plot)
+	if err != nil {
+		logger.Errorf("error serializing plot of plot order %s: %v", po.ID, err)
+		return
+	}
+
+	rec.Plot = plot
+	rec.Interval = po.Interval.String()
+	rec.Order = order
+
+	alert, err := marshalAlert(po.Alert)
+	if err != nil {
+		logger.Errorf("error marshalling alert of plot order %s: %v", po.ID, err)
+		return
+	}
+
+	rec.Alert = alert
+
+	trigger, err := marshalTrigger(po.Trigger)
+	if err != nil {
+		logger.Errorf("error marshalling trigger of plot order %s: %v", po.ID, err)
+		return
+	}
+
+	rec.Trigger = trigger
+	rec.Status = string(po.Status)
+	rec.LastTick = po.LastTick
+
+	history, err := json.Marshal(po.History)
+	if err != nil {
+		logger.Errorf("error marshalling history of plot order %s: %v", po.ID, err)
+		return
+	}
+
+	rec.History = history
+
+	if err := db.SavePlotOrder(rec); err != nil {
+		logger.Errorf("error persisting plot order %s: %v", po.ID, err)
+	}
+}
+
+// persistBracket creates the record of a bracket and of its entry plot order,
+// the exit plot orders are persisted by persistBracketUpdate once they are placed
+func persistBracket(s *session, b *plotor.Bracket, cbr createBracketRequest) error {
+	if db == nil {
+		return nil
+	}
+
+	takeProfit, err := json.Marshal(cbr.TakeProfit)
+	if err != nil {
+		return fmt.Errorf("error marshalling take-profit: %w", err)
+	}
+
+	stopLoss, err := json.Marshal(cbr.StopLoss)
+	if err != nil {
+		return fmt.Errorf("error marshalling stop-loss: %w", err)
+	}
+
+	entry, err := s.PlotOrderer.Get(context.Background(), b.EntryID)
+	if err != nil {
+		return fmt.Errorf("error getting entry: %w", err)
+	}
+
+	persistMu.Lock()
+
+	if !entry.Status.Terminal() {
+		if err := savePlotOrder(s, entry, cbr.Entry.plotOrderOptions); err != nil {
+			persistMu.Unlock()
+			return fmt.Errorf("error persisting entry: %w", err)
+		}
+	}
+
+	err = db.SaveBracket(store.Bracket{
+		ID:           b.ID,
+		SessionToken: s.Token(),
+		Status:       string(b.Status),
+		EntryID:      b.EntryID,
+		TakeProfitID: b.TakeProfitID,
+		StopLossID:   b.StopLossID,
+		Interval:     b.Interval.String(),
+		TakeProfit:   takeProfit,
+		StopLoss:     stopLoss,
+	})
+	persistMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	// the bracket could have changed before its record was created
+	current, err := s.PlotOrderer.Bracket(b.ID)
+	if err != nil {
+		return err
+	}
+
+	s.persistBracketUpdate(current)
+
+	return nil
+}
+
+// persistBracketUpdate updates the status of a persisted bracket and persists its exit plot orders once they are placed,
+// brackets that reached a terminal status are removed and brackets that were not persisted yet are skipped
+func (s *session) persistBracketUpdate(b *plotor.Bracket) {
+	if db == nil {
+		return
+	}
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	if b.Status.Terminal() {
+		if err := db.DeleteBracket(b.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			logger.Errorf("error removing persisted bracket %s: %v", b.ID, err)
+		}
+
+		return
+	}
+
+	rec, err := db.Bracket(b.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return
+	}
+
+	if err != nil {
+		logger.Errorf("error loading persisted bracket %s: %v", b.ID, err)
+		return
+	}
+
+	if b.Status == plotor.BracketStatusOpen {
+		for id, leg := range map[string]json.RawMessage{b.TakeProfitID: rec.TakeProfit, b.StopLossID: rec.StopLoss} {
+			if err := s.persistExit(id, leg); err != nil {
+				logger.Errorf("error persisting exit %s of bracket %s: %v", id, b.ID, err)
+			}
+		}
+	}
+
+	rec.Status = string(b.Status)
+	rec.EntryPartiallyFilled = b.EntryPartiallyFilled
+	rec.Error = ""
+	if b.Err != nil {
+		rec.Error = b.Err.Error()
+	}
+
+	if err := db.SaveBracket(rec); err != nil {
+		logger.Errorf("error persisting bracket %s: %v", b.ID, err)
+	}
+}
+
+// persistExit creates the record of an exit plot order unless it exists already or the exit is closed, persistMu has to be locked
+func (s *session) persistExit(plotOrderID string, leg json.RawMessage) error {
+	if _, err := db.PlotOrder(plotOrderID); !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	po, err := s.PlotOrderer.Get(context.Background(), plotOrderID)
+	if err != nil {
+		return err
+	}
+
+	if po.Status.Terminal() {
+		return nil
+	}
+
+	lr := bracketLegRequest{}
+	if err := json.Unmarshal(leg, &lr); err != nil {
+		return fmt.Errorf("error unmarshalling leg: %w", err)
+	}
+
+	return savePlotOrder(s, po, lr.plotOrderOptions)
+}
+
+// marshalAlert returns nil when the plot order is not an alert
+func marshalAlert(alert *plotor.Alert) (json.RawMessage, error) {
+	if alert == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling alert: %w", err)
+	}
+
+	return data, nil
+}
+
+// triggerRecord is the persisted form of plotor.Trigger
+type triggerRecord struct {
+	Symbol      string
+	Plot        json.RawMessage
+	Direction   plotor.AlertSide
+	Side        plotor.AlertSide
+	MarketPrice float64
+	Triggered   time.Time
+	OrderData   json.RawMessage
+}
+
+// marshalTrigger returns nil when the plot order has no trigger
+func marshalTrigger(t *plotor.Trigger) (json.RawMessage, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	plot, err := geometry.ToJSON(t.Plot)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing trigger plot: %w", err)
+	}
+
+	orderData, err := json.Marshal(t.OrderData)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling trigger order data: %w", err)
+	}
+
+	data, err := json.Marshal(triggerRecord{
+		Symbol:      t.Symbol,
+		Plot:        plot,
+		Direction:   t.Direction,
+		Side:        t.Side,
+		MarketPrice: t.MarketPrice,
+		Triggered:   t.Triggered,
+		OrderData:   orderData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling trigger: %w", err)
+	}
+
+	return data, nil
+}
+
+func unmarshalTrigger(data json.RawMessage) (*plotor.Trigger, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	rec := triggerRecord{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("error unmarshalling trigger: %w", err)
+	}
+
+	plot, err := geometry.FromJSON(rec.Plot)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing trigger plot: %w", err)
+	}
+
+	return &plotor.Trigger{
+		Symbol:      rec.Symbol,
+		Plot:        plot,
+		Direction:   rec.Direction,
+		Side:        rec.Side,
+		MarketPrice: rec.MarketPrice,
+		Triggered:   rec.Triggered,
+		OrderData:   rec.OrderData,
+	}, nil
+}
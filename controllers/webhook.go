@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/H3Cki/Plotor/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+type createWebhookRequest struct {
+	// URL has to point to a public host, loopback and private addresses are rejected
+	URL    string
+	Secret string
+	// Events lists the event types the webhook is notified about e.g. FILLED, ERROR, OUT_OF_RANGE, all events are sent when it's empty
+	Events []string
+}
+
+// webhookItem is a webhook without its secret
+type webhookItem struct {
+	WebhookID string
+	URL       string
+	Events    []plotor.EventType
+}
+
+func newWebhookItem(w webhook.Webhook) webhookItem {
+	return webhookItem{WebhookID: w.ID, URL: w.URL, Events: w.Events}
+}
+
+type webhookResponse struct {
+	Webhook *webhookItem `json:",omitempty"`
+	Error   string
+}
+
+type listWebhooksResponse struct {
+	Webhooks []webhookItem
+	Error    string
+}
+
+type webhookDeliveriesResponse struct {
+	Deliveries []webhook.Delivery
+	Error      string
+}
+
+var eventTypes = []plotor.EventType{
+	plotor.EventCreated,
+	plotor.EventTriggered,
+	plotor.EventRepriced,
+	plotor.EventPartiallyFilled,
+	plotor.EventOutOfRange,
+	plotor.EventFilled,
+	plotor.EventStopped,
+	plotor.EventError,
+	plotor.EventCrossedAbove,
+	plotor.EventCrossedBelow,
+}
+
+func parseEventType(s string) (plotor.EventType, error) {
+	typ := plotor.EventType(strings.ToUpper(strings.TrimSpace(s)))
+
+	for _, known := range eventTypes {
+		if typ == known {
+			return typ, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported event type: %s", s)
+}
+
+// CreateWebhook registers a webhook notified about events of plot orders of the session
+func CreateWebhook() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, webhookResponse{Error: "session does not exist"})
+			return
+		}
+
+		cwr := createWebhookRequest{}
+		if err := c.BindJSON(&cwr); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, webhookResponse{Error: fmt.Sprintf("error marshalling request body: %s", err.Error())})
+			return
+		}
+
+		w := webhook.Webhook{URL: cwr.URL, Secret: cwr.Secret}
+		for _, event := range cwr.Events {
+			typ, err := parseEventType(event)
+			if err != nil {
+				c.IndentedJSON(http.StatusBadRequest, webhookResponse{Error: err.Error()})
+				return
+			}
+
+			w.Events = append(w.Events, typ)
+		}
+
+		w, err := session.webhooks.Add(w)
+		if err != nil {
+			c.IndentedJSON(http.StatusBadRequest, webhookResponse{Error: fmt.Sprintf("error adding webhook: %s", err.Error())})
+			return
+		}
+
+		item := newWebhookItem(w)
+
+		if err := persistSession(session); err != nil {
+			c.IndentedJSON(http.StatusInternalServerError, webhookResponse{Webhook: &item, Error: fmt.Sprintf("error persisting webhook: %s", err.Error())})
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, webhookResponse{Webhook: &item})
+	}
+}
+
+func ListWebhooks() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, listWebhooksResponse{Error: "session does not exist"})
+			return
+		}
+
+		items := []webhookItem{}
+		for _, w := range session.webhooks.Webhooks() {
+			items = append(items, newWebhookItem(w))
+		}
+
+		c.IndentedJSON(http.StatusOK, listWebhooksResponse{Webhooks: items})
+	}
+}
+
+func DeleteWebhook() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("session does not exist")))
+			return
+		}
+
+		webhookID := c.Query("id")
+		if webhookID == "" {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("id can not be empty")))
+			return
+		}
+
+		if err := session.webhooks.Remove(webhookID); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, newErrResponse(fmt.Errorf("error removing webhook: %v", err)))
+			return
+		}
+
+		if err := persistSession(session); err != nil {
+			c.IndentedJSON(http.StatusInternalServerError, newErrResponse(fmt.Errorf("error persisting session: %v", err)))
+			return
+		}
+
+		c.IndentedJSON(http.StatusOK, errResponse{})
+	}
+}
+
+// WebhookDeliveries returns the delivery log of the session, it can be narrowed down
+// to a webhook and limited to the most recent deliveries using id and limit query params
+func WebhookDeliveries() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		session, ok := sessions.get(auth)
+		if !ok {
+			c.IndentedJSON(http.StatusBadRequest, webhookDeliveriesResponse{Error: "session does not exist"})
+			return
+		}
+
+		limit := 0
+		if limitParam := c.Query("limit"); limitParam != "" {
+			n, err := strconv.Atoi(limitParam)
+			if err != nil {
+				c.IndentedJSON(http.StatusBadRequest, webhookDeliveriesResponse{Error: fmt.Sprintf("invalid limit param: %s", err.Error())})
+				return
+			}
+
+			limit = n
+		}
+
+		c.IndentedJSON(http.StatusOK, webhookDeliveriesResponse{Deliveries: session.webhooks.Deliveries(c.Query("id"), limit)})
+	}
+}
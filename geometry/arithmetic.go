@@ -0,0 +1,312 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Validity decides when a combinator of several plots is valid, errors other than ErrOutOfRange are always returned
+type Validity string
+
+const (
+	// ValidAll - the combinator is out of range when any of its plots is out of range, it's used when Validity is empty
+	ValidAll Validity = "all"
+	// ValidAny - plots that are out of range are skipped, the combinator is out of range only when all of them are
+	ValidAny Validity = "any"
+)
+
+func (v Validity) Validate() error {
+	switch v {
+	case "", ValidAll, ValidAny:
+		return nil
+	}
+
+	return fmt.Errorf("unknown validity: %s", v)
+}
+
+// values returns the values of the plots at t and which of them are valid, with ValidAny plots that are out of range are skipped
+func values(t time.Time, validity Validity, plots ...Plot) ([]float64, []bool, error) {
+	vs := make([]float64, len(plots))
+	valid := make([]bool, len(plots))
+	n := 0
+
+	for i, p := range plots {
+		v, err := p.At(t)
+		if errors.Is(err, ErrOutOfRange) && validity == ValidAny {
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		vs[i], valid[i] = v, true
+		n++
+	}
+
+	if n == 0 {
+		return nil, nil, ErrOutOfRange
+	}
+
+	return vs, valid, nil
+}
+
+// Add is a plot combinator which returns the sum of the plots
+type Add struct {
+	Plots    []Plot
+	Validity Validity
+}
+
+// NewAdd is a constructor for Add, returns error if provided plot list is empty
+func NewAdd(plots []Plot, validity Validity) (*Add, error) {
+	if len(plots) == 0 {
+		return nil, errors.New("error creating add combinator: empty plot list")
+	}
+
+	if err := validity.Validate(); err != nil {
+		return nil, fmt.Errorf("error creating add combinator: %w", err)
+	}
+
+	return &Add{Plots: plots, Validity: validity}, nil
+}
+
+func (a *Add) At(t time.Time) (float64, error) {
+	vs, valid, err := values(t, a.Validity, a.Plots...)
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	for i, v := range vs {
+		if valid[i] {
+			sum += v
+		}
+	}
+
+	return sum, nil
+}
+
+// Sub is a plot combinator which returns the difference of two plots, the minuend has to be valid
+// and Validity applies to the subtrahend, with ValidAny the minuend is returned when the subtrahend is out of range
+type Sub struct {
+	Minuend, Subtrahend Plot
+	Validity            Validity
+}
+
+// NewSub is a constructor for Sub
+func NewSub(minuend, subtrahend Plot, validity Validity) (*Sub, error) {
+	if minuend == nil || subtrahend == nil {
+		return nil, errors.New("error creating sub combinator: plots can not be empty")
+	}
+
+	if err := validity.Validate(); err != nil {
+		return nil, fmt.Errorf("error creating sub combinator: %w", err)
+	}
+
+	return &Sub{Minuend: minuend, Subtrahend: subtrahend, Validity: validity}, nil
+}
+
+func (s *Sub) At(t time.Time) (float64, error) {
+	minuend, err := s.Minuend.At(t)
+	if err != nil {
+		return 0, err
+	}
+
+	subtrahend, err := s.Subtrahend.At(t)
+	if errors.Is(err, ErrOutOfRange) && s.Validity == ValidAny {
+		return minuend, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return minuend - subtrahend, nil
+}
+
+// Mul is a plot combinator which returns the product of the plots
+type Mul struct {
+	Plots    []Plot
+	Validity Validity
+}
+
+// NewMul is a constructor for Mul, returns error if provided plot list is empty
+func NewMul(plots []Plot, validity Validity) (*Mul, error) {
+	if len(plots) == 0 {
+		return nil, errors.New("error creating mul combinator: empty plot list")
+	}
+
+	if err := validity.Validate(); err != nil {
+		return nil, fmt.Errorf("error creating mul combinator: %w", err)
+	}
+
+	return &Mul{Plots: plots, Validity: validity}, nil
+}
+
+func (m *Mul) At(t time.Time) (float64, error) {
+	vs, valid, err := values(t, m.Validity, m.Plots...)
+	if err != nil {
+		return 0, err
+	}
+
+	product := 1.0
+	for i, v := range vs {
+		if valid[i] {
+			product *= v
+		}
+	}
+
+	return product, nil
+}
+
+// Scale is a plot wrapper which multiplies the plot by a constant factor
+type Scale struct {
+	Factor float64
+	Plot   Plot
+}
+
+func NewScale(plot Plot, factor float64) *Scale {
+	return &Scale{Factor: factor, Plot: plot}
+}
+
+func (s *Scale) At(t time.Time) (float64, error) {
+	v, err := s.Plot.At(t)
+	if err != nil {
+		return 0, err
+	}
+
+	return v * s.Factor, nil
+}
+
+// WeightedAverage is a plot combinator which returns the weighted average of the plots, e.g. the midpoint of two trendlines.
+// With ValidAny the average is taken over the valid plots only.
+type WeightedAverage struct {
+	Plots    []Plot
+	Weights  []float64
+	Validity Validity
+}
+
+// NewWeightedAverage is a constructor for WeightedAverage, every plot needs a non-negative weight and the weights can not all be zero
+func NewWeightedAverage(plots []Plot, weights []float64, validity Validity) (*WeightedAverage, error) {
+	if len(plots) == 0 {
+		return nil, errors.New("error creating weighted average: empty plot list")
+	}
+
+	if len(plots) != len(weights) {
+		return nil, fmt.Errorf("error creating weighted average: %d plots and %d weights", len(plots), len(weights))
+	}
+
+	total := 0.0
+	for i, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("error creating weighted average: invalid weight %d: %f", i, w)
+		}
+
+		total += w
+	}
+
+	if total == 0 {
+		return nil, errors.New("error creating weighted average: all weights are zero")
+	}
+
+	if err := validity.Validate(); err != nil {
+		return nil, fmt.Errorf("error creating weighted average: %w", err)
+	}
+
+	return &WeightedAverage{Plots: plots, Weights: weights, Validity: validity}, nil
+}
+
+func (a *WeightedAverage) At(t time.Time) (float64, error) {
+	vs, valid, err := values(t, a.Validity, a.Plots...)
+	if err != nil {
+		return 0, err
+	}
+
+	sum, total := 0.0, 0.0
+	for i, v := range vs {
+		if valid[i] {
+			sum += v * a.Weights[i]
+			total += a.Weights[i]
+		}
+	}
+
+	// only plots with zero weight are valid
+	if total == 0 {
+		return 0, ErrOutOfRange
+	}
+
+	return sum / total, nil
+}
+
+// Clamp is a plot wrapper which keeps the plot between a floor and a ceiling plot, the ceiling wins if the floor is above it.
+// The plot has to be valid and Validity applies to the bounds, with ValidAny a bound that is out of range is not applied.
+// Floor or Ceil can be nil in which case the plot is not bounded on that side.
+type Clamp struct {
+	Plot        Plot
+	Floor, Ceil Plot
+	Validity    Validity
+}
+
+// NewClamp is a constructor for Clamp, returns error if the plot is nil or there are no bounds
+func NewClamp(plot, floor, ceil Plot, validity Validity) (*Clamp, error) {
+	if plot == nil {
+		return nil, errors.New("error creating clamp: plot can not be empty")
+	}
+
+	if floor == nil && ceil == nil {
+		return nil, errors.New("error creating clamp: floor and ceil can not both be empty")
+	}
+
+	if err := validity.Validate(); err != nil {
+		return nil, fmt.Errorf("error creating clamp: %w", err)
+	}
+
+	return &Clamp{Plot: plot, Floor: floor, Ceil: ceil, Validity: validity}, nil
+}
+
+func (c *Clamp) At(t time.Time) (float64, error) {
+	v, err := c.Plot.At(t)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.Floor != nil {
+		floor, err := c.bound(c.Floor, t)
+		if err != nil {
+			return 0, err
+		}
+
+		if floor != nil && v < *floor {
+			v = *floor
+		}
+	}
+
+	if c.Ceil != nil {
+		ceil, err := c.bound(c.Ceil, t)
+		if err != nil {
+			return 0, err
+		}
+
+		if ceil != nil && v > *ceil {
+			v = *ceil
+		}
+	}
+
+	return v, nil
+}
+
+// bound returns the value of the bound at t, nil if it's out of range and skipped
+func (c *Clamp) bound(p Plot, t time.Time) (*float64, error) {
+	v, err := p.At(t)
+	if errors.Is(err, ErrOutOfRange) && c.Validity == ValidAny {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
@@ -0,0 +1,125 @@
+package geometry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+// failing returns an error other than ErrOutOfRange
+type failing struct{}
+
+func (failing) At(time.Time) (float64, error) {
+	return 0, errors.New("failing")
+}
+
+func TestNewCombinators(t *testing.T) {
+	one := &geometry.Line{B: 1}
+
+	tests := []struct {
+		name string
+		new  func() (geometry.Plot, error)
+	}{
+		{"add - empty", func() (geometry.Plot, error) { return geometry.NewAdd(nil, geometry.ValidAll) }},
+		{"add - unknown validity", func() (geometry.Plot, error) { return geometry.NewAdd([]geometry.Plot{one}, "some") }},
+		{"mul - empty", func() (geometry.Plot, error) { return geometry.NewMul(nil, geometry.ValidAny) }},
+		{"sub - nil", func() (geometry.Plot, error) { return geometry.NewSub(one, nil, geometry.ValidAll) }},
+		{"weighted average - empty", func() (geometry.Plot, error) { return geometry.NewWeightedAverage(nil, nil, geometry.ValidAll) }},
+		{"weighted average - missing weight", func() (geometry.Plot, error) {
+			return geometry.NewWeightedAverage([]geometry.Plot{one, one}, []float64{1}, geometry.ValidAll)
+		}},
+		{"weighted average - negative weight", func() (geometry.Plot, error) {
+			return geometry.NewWeightedAverage([]geometry.Plot{one, one}, []float64{2, -1}, geometry.ValidAll)
+		}},
+		{"weighted average - zero weights", func() (geometry.Plot, error) {
+			return geometry.NewWeightedAverage([]geometry.Plot{one, one}, []float64{0, 0}, geometry.ValidAll)
+		}},
+		{"clamp - no bounds", func() (geometry.Plot, error) { return geometry.NewClamp(one, nil, nil, geometry.ValidAll) }},
+		{"clamp - nil plot", func() (geometry.Plot, error) { return geometry.NewClamp(nil, one, nil, geometry.ValidAll) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.new()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCombinators_At(t *testing.T) {
+	must := mustPlot(t)
+
+	ten := &geometry.Line{B: 10}
+	four := &geometry.Line{B: 4}
+	invalid := &neverValid{100}
+
+	tests := []struct {
+		name string
+		plot geometry.Plot
+		want float64
+		err  error
+	}{
+		{name: "add", plot: must(geometry.NewAdd([]geometry.Plot{ten, four}, geometry.ValidAll)), want: 14},
+		{name: "add - all, invalid", plot: must(geometry.NewAdd([]geometry.Plot{ten, invalid}, geometry.ValidAll)), err: geometry.ErrOutOfRange},
+		{name: "add - default is all", plot: must(geometry.NewAdd([]geometry.Plot{ten, invalid}, "")), err: geometry.ErrOutOfRange},
+		{name: "add - any, invalid", plot: must(geometry.NewAdd([]geometry.Plot{ten, invalid, four}, geometry.ValidAny)), want: 14},
+		{name: "add - any, all invalid", plot: must(geometry.NewAdd([]geometry.Plot{invalid}, geometry.ValidAny)), err: geometry.ErrOutOfRange},
+		{name: "sub", plot: must(geometry.NewSub(ten, four, geometry.ValidAll)), want: 6},
+		{name: "sub - all, invalid subtrahend", plot: must(geometry.NewSub(ten, invalid, geometry.ValidAll)), err: geometry.ErrOutOfRange},
+		{name: "sub - any, invalid subtrahend", plot: must(geometry.NewSub(ten, invalid, geometry.ValidAny)), want: 10},
+		{name: "sub - any, invalid minuend", plot: must(geometry.NewSub(invalid, four, geometry.ValidAny)), err: geometry.ErrOutOfRange},
+		{name: "mul", plot: must(geometry.NewMul([]geometry.Plot{ten, four}, geometry.ValidAll)), want: 40},
+		{name: "mul - any, invalid", plot: must(geometry.NewMul([]geometry.Plot{invalid, four}, geometry.ValidAny)), want: 4},
+		{name: "scale", plot: geometry.NewScale(ten, 0.5), want: 5},
+		{name: "scale - invalid", plot: geometry.NewScale(invalid, 0.5), err: geometry.ErrOutOfRange},
+		{name: "midpoint", plot: must(geometry.NewWeightedAverage([]geometry.Plot{ten, four}, []float64{1, 1}, geometry.ValidAll)), want: 7},
+		{name: "weighted average", plot: must(geometry.NewWeightedAverage([]geometry.Plot{ten, four}, []float64{1, 2}, geometry.ValidAll)), want: 6},
+		{
+			name: "weighted average - any, invalid",
+			plot: must(geometry.NewWeightedAverage([]geometry.Plot{ten, invalid}, []float64{1, 2}, geometry.ValidAny)),
+			want: 10,
+		},
+		{
+			name: "weighted average - any, only zero weight valid",
+			plot: must(geometry.NewWeightedAverage([]geometry.Plot{ten, invalid}, []float64{0, 2}, geometry.ValidAny)),
+			err:  geometry.ErrOutOfRange,
+		},
+		{name: "clamp - below floor", plot: must(geometry.NewClamp(four, ten, nil, geometry.ValidAll)), want: 10},
+		{name: "clamp - above ceil", plot: must(geometry.NewClamp(ten, nil, four, geometry.ValidAll)), want: 4},
+		{name: "clamp - between", plot: must(geometry.NewClamp(&geometry.Line{B: 7}, four, ten, geometry.ValidAll)), want: 7},
+		{name: "clamp - ceil wins", plot: must(geometry.NewClamp(&geometry.Line{B: 7}, ten, four, geometry.ValidAll)), want: 4},
+		{name: "clamp - all, invalid bound", plot: must(geometry.NewClamp(four, invalid, ten, geometry.ValidAll)), err: geometry.ErrOutOfRange},
+		{name: "clamp - any, invalid bound", plot: must(geometry.NewClamp(four, invalid, &geometry.Line{B: 2}, geometry.ValidAny)), want: 2},
+		{name: "clamp - any, invalid plot", plot: must(geometry.NewClamp(invalid, four, ten, geometry.ValidAny)), err: geometry.ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.plot.At(time.Unix(0, 0))
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCombinators_AtError(t *testing.T) {
+	must := mustPlot(t)
+
+	// errors other than ErrOutOfRange are returned regardless of validity
+	plots := []geometry.Plot{
+		must(geometry.NewAdd([]geometry.Plot{&geometry.Line{B: 1}, failing{}}, geometry.ValidAny)),
+		must(geometry.NewMul([]geometry.Plot{failing{}}, geometry.ValidAny)),
+		must(geometry.NewSub(&geometry.Line{B: 1}, failing{}, geometry.ValidAny)),
+		must(geometry.NewWeightedAverage([]geometry.Plot{failing{}}, []float64{1}, geometry.ValidAny)),
+		must(geometry.NewClamp(&geometry.Line{B: 1}, failing{}, nil, geometry.ValidAny)),
+	}
+
+	for _, p := range plots {
+		_, err := p.At(time.Unix(0, 0))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, geometry.ErrOutOfRange)
+	}
+}
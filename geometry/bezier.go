@@ -0,0 +1,121 @@
+package geometry
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// bezierIterations is the number of bisection steps used to find the curve parameter of a time, it's far below a second
+const bezierIterations = 64
+
+// Bezier is a smooth curve going through all of its points, consecutive points are connected with cubic Bézier segments
+// whose control points are derived from the Catmull-Rom tangents at the points. The range is [first point, last point),
+// if ExtendLeft or ExtendRight is true the curve extends indefinitely along its tangent at the first or the last point.
+// Log curves are drawn on semi-logarithmic (x, log10) graph.
+type Bezier struct {
+	Points                  []Point
+	ExtendLeft, ExtendRight bool
+	Log                     bool
+	// xs, ys are the coordinates of the points, ys are log10 of the prices of log curves
+	xs, ys []float64
+}
+
+// NewBezier is a Bezier constructor, it accepts slice of at least 2 points with unique dates which are then sorted by time
+func NewBezier(points []Point, extendLeft, extendRight bool) (*Bezier, error) {
+	return newBezier(points, extendLeft, extendRight, false)
+}
+
+// NewLogBezier is a Bezier constructor for log scale, it accepts the same arguments as NewBezier, prices have to be positive
+func NewLogBezier(points []Point, extendLeft, extendRight bool) (*Bezier, error) {
+	return newBezier(points, extendLeft, extendRight, true)
+}
+
+func newBezier(points []Point, extendLeft, extendRight, log bool) (*Bezier, error) {
+	points, xs, ys, err := curvePoints(points, log)
+	if err != nil {
+		return nil, fmt.Errorf("error creating bezier: %w", err)
+	}
+
+	return &Bezier{Points: points, ExtendLeft: extendLeft, ExtendRight: extendRight, Log: log, xs: xs, ys: ys}, nil
+}
+
+func (b *Bezier) At(t time.Time) (float64, error) {
+	x := timeToFloat64(t)
+	n := len(b.xs)
+
+	if n < 2 || len(b.ys) != n {
+		return 0, errCurveNotPrepared
+	}
+
+	// the tangents at the ends point to the neighbouring points
+	leftSlope := (b.ys[1] - b.ys[0]) / (b.xs[1] - b.xs[0])
+	rightSlope := (b.ys[n-1] - b.ys[n-2]) / (b.xs[n-1] - b.xs[n-2])
+
+	if y, ok, err := extendCurve(x, b.xs, b.ys, leftSlope, rightSlope, b.ExtendLeft, b.ExtendRight); err != nil {
+		return 0, err
+	} else if ok {
+		return curvePrice(y, b.Log), nil
+	}
+
+	// the segment [xs[k], xs[k+1]) containing x
+	k := sort.Search(n, func(i int) bool { return b.xs[i] > x }) - 1
+	cxs, cys := b.segment(k)
+
+	// the dates of the control points of a segment are increasing so the time grows with the curve parameter
+	lo, hi := 0.0, 1.0
+	for i := 0; i < bezierIterations; i++ {
+		mid := (lo + hi) / 2
+		if deCasteljau(cxs, mid) < x {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return curvePrice(deCasteljau(cys, (lo+hi)/2), b.Log), nil
+}
+
+// segment returns the coordinates of the 4 control points of the segment between the points k and k+1
+func (b *Bezier) segment(k int) ([]float64, []float64) {
+	h := b.xs[k+1] - b.xs[k]
+	outX, outY := b.handle(k, h)
+	inX, inY := b.handle(k+1, h)
+
+	return []float64{b.xs[k], b.xs[k] + outX, b.xs[k+1] - inX, b.xs[k+1]},
+		[]float64{b.ys[k], b.ys[k] + outY, b.ys[k+1] - inY, b.ys[k+1]}
+}
+
+// handle returns the offset of the control point next to the point i in a segment of width h. It's a sixth of the
+// Catmull-Rom tangent, the vector between the neighbouring points, shortened to at most a third of the segment width
+// so the dates of the control points keep increasing.
+func (b *Bezier) handle(i int, h float64) (float64, float64) {
+	prev, next := i-1, i+1
+	if prev < 0 {
+		prev = 0
+	}
+
+	if next > len(b.xs)-1 {
+		next = len(b.xs) - 1
+	}
+
+	dx, dy := (b.xs[next]-b.xs[prev])/6, (b.ys[next]-b.ys[prev])/6
+	if limit := h / 3; dx > limit {
+		dx, dy = limit, dy*limit/dx
+	}
+
+	return dx, dy
+}
+
+// deCasteljau evaluates the Bézier polynomial with given coefficients at u
+func deCasteljau(coefs []float64, u float64) float64 {
+	c := append([]float64{}, coefs...)
+
+	for k := len(c) - 1; k > 0; k-- {
+		for i := 0; i < k; i++ {
+			c[i] = (1-u)*c[i] + u*c[i+1]
+		}
+	}
+
+	return c[0]
+}
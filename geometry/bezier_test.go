@@ -0,0 +1,122 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBezier(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []geometry.Point
+		expectErr bool
+	}{
+		{"no points", nil, true},
+		{"1 point", []geometry.Point{{time.Unix(0, 0), 1}}, true},
+		{"2 points", []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}}, false},
+		{"same date", []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(0, 0), 2}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := geometry.NewBezier(tt.points, false, false)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, b == nil)
+
+			lb, err := geometry.NewLogBezier(tt.points, false, false)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, lb == nil)
+		})
+	}
+}
+
+func TestBezier_At(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(0, 0), 0},
+		{time.Unix(10, 0), 20},
+		{time.Unix(20, 0), 0},
+	}
+
+	tests := []struct {
+		name                    string
+		extendLeft, extendRight bool
+		x                       time.Time
+		y                       float64
+		err                     error
+	}{
+		{name: "first point", x: time.Unix(0, 0), y: 0},
+		{name: "middle point", x: time.Unix(10, 0), y: 20},
+		{name: "between points", x: time.Unix(5, 0), y: 12.741266790854493},
+		{name: "symmetric", x: time.Unix(15, 0), y: 12.741266790854493},
+		{name: "last point", extendRight: true, x: time.Unix(20, 0), y: 0},
+		{name: "left - not extended", x: time.Unix(-1, 0), err: geometry.ErrOutOfRange},
+		{name: "right - not extended", x: time.Unix(20, 0), err: geometry.ErrOutOfRange},
+		{name: "left - extended", extendLeft: true, x: time.Unix(-5, 0), y: -10},
+		{name: "right - extended", extendRight: true, x: time.Unix(25, 0), y: -10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := geometry.NewBezier(points, tt.extendLeft, tt.extendRight)
+			assert.NoError(t, err)
+
+			y, err := b.At(tt.x)
+			assert.ErrorIs(t, err, tt.err)
+			assert.InDelta(t, tt.y, y, 1e-6)
+		})
+	}
+}
+
+func TestBezier_AtPoints(t *testing.T) {
+	// unevenly spaced points, the curve has to go through each of them
+	points := []geometry.Point{
+		{time.Unix(0, 0), 10},
+		{time.Unix(3, 0), 30},
+		{time.Unix(20, 0), 5},
+		{time.Unix(24, 0), 12},
+		{time.Unix(60, 0), 40},
+	}
+
+	b, err := geometry.NewBezier(points, false, true)
+	assert.NoError(t, err)
+
+	for _, p := range points {
+		y, err := b.At(p.Date)
+		assert.NoError(t, err)
+		assert.InDelta(t, p.Price, y, 1e-6)
+	}
+}
+
+func TestBezier_AtZeroValue(t *testing.T) {
+	_, err := (&geometry.Bezier{}).At(time.Now())
+	assert.Error(t, err)
+}
+
+func TestLogBezier_At(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(0, 0), 1},
+		{time.Unix(10, 0), 100},
+		{time.Unix(20, 0), 1},
+	}
+
+	b, err := geometry.NewLogBezier(points, false, true)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		x time.Time
+		y float64
+	}{
+		{x: time.Unix(0, 0), y: 1},
+		{x: time.Unix(10, 0), y: 100},
+		{x: time.Unix(20, 0), y: 1},
+	}
+
+	for _, tt := range tests {
+		y, err := b.At(tt.x)
+		assert.NoError(t, err)
+		assert.InDelta(t, tt.y, y, 1e-6)
+	}
+}
@@ -0,0 +1,120 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Rail selects one of the lines of a Channel or a Pitchfork
+type Rail string
+
+const (
+	RailUpper Rail = "upper"
+	RailLower Rail = "lower"
+	RailMid   Rail = "mid"
+)
+
+func (r Rail) Validate() error {
+	switch r {
+	case RailUpper, RailLower, RailMid:
+		return nil
+	}
+
+	return fmt.Errorf("unknown rail: %s", r)
+}
+
+// Channel is a base line and a line parallel to it drawn through a third point, At returns the price of the selected rail.
+// The upper and lower rails are the higher and the lower of the two lines, the mid rail is halfway between them.
+// Channels built with NewLogChannel are parallel on semi-logarithmic graph and their mid rail is the geometric mean.
+type Channel struct {
+	Base, Parallel Plot
+	Rail           Rail
+	// through is the point the parallel line was drawn through, it's kept to serialize the channel
+	through Point
+}
+
+// NewChannel creates a channel from a base Line through p0 and p1 and a parallel line through p2,
+// extendLeft and extendRight apply to both lines
+func NewChannel(p0, p1, p2 Point, rail Rail, extendLeft, extendRight bool) (*Channel, error) {
+	if err := rail.Validate(); err != nil {
+		return nil, fmt.Errorf("error creating channel: %w", err)
+	}
+
+	base, err := NewLine(p0, p1, extendLeft, extendRight)
+	if err != nil {
+		return nil, fmt.Errorf("error creating channel base: %w", err)
+	}
+
+	return &Channel{Base: base, Parallel: parallelLine(base, p2), Rail: rail, through: p2}, nil
+}
+
+// NewLogChannel creates a channel from a base LogLine through p0 and p1 and a parallel LogLine through p2
+func NewLogChannel(p0, p1, p2 Point, rail Rail, extendLeft, extendRight bool) (*Channel, error) {
+	if err := rail.Validate(); err != nil {
+		return nil, fmt.Errorf("error creating channel: %w", err)
+	}
+
+	base, err := NewLogLine(p0, p1, extendLeft, extendRight)
+	if err != nil {
+		return nil, fmt.Errorf("error creating channel base: %w", err)
+	}
+
+	return &Channel{Base: base, Parallel: parallelLogLine(base, p2), Rail: rail, through: p2}, nil
+}
+
+func (c *Channel) At(t time.Time) (float64, error) {
+	_, log := c.Base.(*LogLine)
+	return railAt(c.Rail, log, c.Base, c.Parallel, t)
+}
+
+// parallelLine returns a line parallel to the line that goes through the point, it has the same limits as the line
+func parallelLine(l *Line, through Point) *Line {
+	p0, p1 := l.points()
+	d := through.Price - (l.A*timeToFloat64(through.Date) + l.B)
+
+	// the dates are different so the line can always be created
+	parallel, _ := NewLine(Point{p0.Date, p0.Price + d}, Point{p1.Date, p1.Price + d}, l.LeftLimit.IsZero(), l.RightLimit.IsZero())
+
+	return parallel
+}
+
+// parallelLogLine returns a log line parallel to the log line that goes through the point, it has the same limits as the log line
+func parallelLogLine(l *LogLine, through Point) *LogLine {
+	p0, p1 := l.points()
+	r := through.Price / (l.K * math.Pow(10, l.M*(timeToFloat64(through.Date)-l.Xoffset)))
+
+	// the dates are different so the line can always be created
+	parallel, _ := NewLogLine(Point{p0.Date, p0.Price * r}, Point{p1.Date, p1.Price * r}, l.LeftLimit.IsZero(), l.RightLimit.IsZero())
+
+	return parallel
+}
+
+// railAt returns the price of the rail between the two plots, the mid rail is the geometric mean of them when log is true
+func railAt(rail Rail, log bool, p0, p1 Plot, t time.Time) (float64, error) {
+	v0, err := p0.At(t)
+	if err != nil {
+		return 0, err
+	}
+
+	v1, err := p1.At(t)
+	if err != nil {
+		return 0, err
+	}
+
+	switch rail {
+	case RailUpper:
+		return math.Max(v0, v1), nil
+	case RailLower:
+		return math.Min(v0, v1), nil
+	case RailMid:
+		if log {
+			return math.Sqrt(v0 * v1), nil
+		}
+
+		return (v0 + v1) / 2, nil
+	}
+
+	return 0, errors.New("unknown rail")
+}
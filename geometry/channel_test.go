@@ -0,0 +1,98 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewChannel(t *testing.T) {
+	p0 := geometry.Point{time.Unix(0, 0), 1}
+	p1 := geometry.Point{time.Unix(10, 0), 10}
+	p2 := geometry.Point{time.Unix(5, 0), 20}
+
+	tests := []struct {
+		name      string
+		p0, p1    geometry.Point
+		rail      geometry.Rail
+		expectErr bool
+	}{
+		{name: "valid", p0: p0, p1: p1, rail: geometry.RailUpper},
+		{name: "unknown rail", p0: p0, p1: p1, rail: "top", expectErr: true},
+		{name: "same date", p0: p0, p1: geometry.Point{time.Unix(0, 0), 10}, rail: geometry.RailLower, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := geometry.NewChannel(tt.p0, tt.p1, p2, tt.rail, false, false)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, c == nil)
+
+			lc, err := geometry.NewLogChannel(tt.p0, tt.p1, p2, tt.rail, false, false)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, lc == nil)
+		})
+	}
+}
+
+func TestChannel_At(t *testing.T) {
+	// the parallel line is 10 above the base line
+	p0 := geometry.Point{time.Unix(0, 0), 0}
+	p1 := geometry.Point{time.Unix(10, 0), 10}
+	p2 := geometry.Point{time.Unix(5, 0), 15}
+
+	tests := []struct {
+		name        string
+		rail        geometry.Rail
+		extendRight bool
+		x           time.Time
+		y           float64
+		err         error
+	}{
+		{name: "upper", rail: geometry.RailUpper, x: time.Unix(5, 0), y: 15},
+		{name: "lower", rail: geometry.RailLower, x: time.Unix(5, 0), y: 5},
+		{name: "mid", rail: geometry.RailMid, x: time.Unix(5, 0), y: 10},
+		{name: "lower - extended", rail: geometry.RailLower, extendRight: true, x: time.Unix(20, 0), y: 20},
+		{name: "lower - not extended", rail: geometry.RailLower, x: time.Unix(20, 0), err: geometry.ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := geometry.NewChannel(p0, p1, p2, tt.rail, false, tt.extendRight)
+			assert.NoError(t, err)
+
+			y, err := c.At(tt.x)
+			assert.ErrorIs(t, err, tt.err)
+			assert.InDelta(t, tt.y, y, 1e-9)
+		})
+	}
+}
+
+func TestLogChannel_At(t *testing.T) {
+	// the parallel line is 10 times the base line
+	p0 := geometry.Point{time.Unix(0, 0), 1}
+	p1 := geometry.Point{time.Unix(2, 0), 100}
+	p2 := geometry.Point{time.Unix(0, 0), 10}
+
+	tests := []struct {
+		rail geometry.Rail
+		y    float64
+	}{
+		{rail: geometry.RailUpper, y: 100},
+		{rail: geometry.RailLower, y: 10},
+		{rail: geometry.RailMid, y: 31.622776601683793},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.rail), func(t *testing.T) {
+			c, err := geometry.NewLogChannel(p0, p1, p2, tt.rail, false, false)
+			assert.NoError(t, err)
+
+			y, err := c.At(time.Unix(1, 0))
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.y, y, 1e-9)
+		})
+	}
+}
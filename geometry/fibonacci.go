@@ -0,0 +1,68 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Fibonacci is a retracement or extension level between two anchor plots, it's Low + Ratio*(High-Low) at every time,
+// e.g. 0.618 retracement or 1.272 extension. On log scale the level is Low * (High/Low)^Ratio which requires positive prices.
+// Fibonacci is valid only when both anchors are valid.
+type Fibonacci struct {
+	Low, High Plot
+	Ratio     float64
+	Log       bool
+}
+
+// NewFibonacci is a constructor for Fibonacci on linear scale
+func NewFibonacci(low, high Plot, ratio float64) *Fibonacci {
+	return &Fibonacci{Low: low, High: high, Ratio: ratio}
+}
+
+// NewLogFibonacci is a constructor for Fibonacci on log scale
+func NewLogFibonacci(low, high Plot, ratio float64) *Fibonacci {
+	return &Fibonacci{Low: low, High: high, Ratio: ratio, Log: true}
+}
+
+// NewFibonacciFromPoints anchors the level to the prices of two swing points, it's valid from the later of the points on
+func NewFibonacciFromPoints(low, high Point, ratio float64, log bool) (*Fibonacci, error) {
+	since := low.Date
+	if high.Date.After(since) {
+		since = high.Date
+	}
+
+	lowPlot, err := NewHorizontal(low.Price, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	highPlot, err := NewHorizontal(high.Price, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fibonacci{Low: lowPlot, High: highPlot, Ratio: ratio, Log: log}, nil
+}
+
+func (f *Fibonacci) At(t time.Time) (float64, error) {
+	low, err := f.Low.At(t)
+	if err != nil {
+		return 0, err
+	}
+
+	high, err := f.High.At(t)
+	if err != nil {
+		return 0, err
+	}
+
+	if !f.Log {
+		return low + f.Ratio*(high-low), nil
+	}
+
+	if low <= 0 || high <= 0 {
+		return 0, fmt.Errorf("log fibonacci requires positive anchors, got low %f and high %f", low, high)
+	}
+
+	return low * math.Pow(high/low, f.Ratio), nil
+}
@@ -0,0 +1,71 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFibonacci_At(t *testing.T) {
+	// the swing goes from 100 to 200 and moves up by 1 every second
+	low := &geometry.Line{A: 1, B: 100}
+	high := &geometry.Line{A: 1, B: 200}
+
+	tests := []struct {
+		name  string
+		ratio float64
+		log   bool
+		x     time.Time
+		y     float64
+	}{
+		{name: "0", ratio: 0, x: time.Unix(0, 0), y: 100},
+		{name: "1", ratio: 1, x: time.Unix(0, 0), y: 200},
+		{name: "0.618", ratio: 0.618, x: time.Unix(0, 0), y: 161.8},
+		{name: "1.272", ratio: 1.272, x: time.Unix(0, 0), y: 227.2},
+		{name: "0.618 - moving swing", ratio: 0.618, x: time.Unix(50, 0), y: 211.8},
+		{name: "log 0.5", ratio: 0.5, log: true, x: time.Unix(0, 0), y: 141.4213562373095},
+		{name: "log 2", ratio: 2, log: true, x: time.Unix(0, 0), y: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := geometry.NewFibonacci(low, high, tt.ratio)
+			if tt.log {
+				f = geometry.NewLogFibonacci(low, high, tt.ratio)
+			}
+
+			y, err := f.At(tt.x)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.y, y, 1e-9)
+		})
+	}
+}
+
+func TestFibonacci_AtInvalid(t *testing.T) {
+	f := geometry.NewFibonacci(&geometry.Line{A: 1}, &neverValid{}, 0.5)
+	_, err := f.At(time.Unix(0, 0))
+	assert.ErrorIs(t, err, geometry.ErrOutOfRange)
+
+	// log scale is undefined for non-positive prices
+	lf := geometry.NewLogFibonacci(&geometry.Line{B: -1}, &geometry.Line{B: 100}, 0.5)
+	_, err = lf.At(time.Unix(0, 0))
+	assert.Error(t, err)
+}
+
+func TestNewFibonacciFromPoints(t *testing.T) {
+	low := geometry.Point{Date: time.Unix(0, 0), Price: 100}
+	high := geometry.Point{Date: time.Unix(10, 0), Price: 200}
+
+	f, err := geometry.NewFibonacciFromPoints(low, high, 0.382, false)
+	assert.NoError(t, err)
+
+	// the level exists once the swing is complete
+	_, err = f.At(time.Unix(9, 0))
+	assert.ErrorIs(t, err, geometry.ErrOutOfRange)
+
+	y, err := f.At(time.Unix(1000, 0))
+	assert.NoError(t, err)
+	assert.InDelta(t, 138.2, y, 1e-9)
+}
@@ -0,0 +1,30 @@
+package geometry
+
+import (
+	"errors"
+	"time"
+)
+
+// Horizontal is a flat level with a constant price, e.g. a support or resistance level.
+// The range is [LeftLimit, RightLimit), zero limits are not applied.
+type Horizontal struct {
+	Price                 float64
+	LeftLimit, RightLimit time.Time
+}
+
+// NewHorizontal is a Horizontal constructor, returns error if both limits are set and the left limit is not before the right one
+func NewHorizontal(price float64, leftLimit, rightLimit time.Time) (*Horizontal, error) {
+	if !leftLimit.IsZero() && !rightLimit.IsZero() && !leftLimit.Before(rightLimit) {
+		return nil, errors.New("error creating horizontal: left limit is not before right limit")
+	}
+
+	return &Horizontal{Price: price, LeftLimit: leftLimit, RightLimit: rightLimit}, nil
+}
+
+func (h *Horizontal) At(t time.Time) (float64, error) {
+	if !lineInRange(t, h.LeftLimit, h.RightLimit) {
+		return 0, ErrOutOfRange
+	}
+
+	return h.Price, nil
+}
@@ -0,0 +1,59 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHorizontal(t *testing.T) {
+	tests := []struct {
+		name                  string
+		leftLimit, rightLimit time.Time
+		expectErr             bool
+	}{
+		{name: "no limits"},
+		{name: "left limit", leftLimit: time.Unix(10, 0)},
+		{name: "right limit", rightLimit: time.Unix(10, 0)},
+		{name: "both limits", leftLimit: time.Unix(10, 0), rightLimit: time.Unix(20, 0)},
+		{name: "same limits", leftLimit: time.Unix(10, 0), rightLimit: time.Unix(10, 0), expectErr: true},
+		{name: "reversed limits", leftLimit: time.Unix(20, 0), rightLimit: time.Unix(10, 0), expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := geometry.NewHorizontal(100, tt.leftLimit, tt.rightLimit)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, h == nil)
+		})
+	}
+}
+
+func TestHorizontal_At(t *testing.T) {
+	tests := []struct {
+		name                  string
+		leftLimit, rightLimit time.Time
+		x                     time.Time
+		y                     float64
+		err                   error
+	}{
+		{name: "no limits", x: time.Unix(-1000, 0), y: 100},
+		{name: "left limit - exact", leftLimit: time.Unix(10, 0), x: time.Unix(10, 0), y: 100},
+		{name: "left limit - before", leftLimit: time.Unix(10, 0), x: time.Unix(9, 0), err: geometry.ErrOutOfRange},
+		{name: "right limit - before", rightLimit: time.Unix(10, 0), x: time.Unix(9, 0), y: 100},
+		{name: "right limit - exact", rightLimit: time.Unix(10, 0), x: time.Unix(10, 0), err: geometry.ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := geometry.NewHorizontal(100, tt.leftLimit, tt.rightLimit)
+			assert.NoError(t, err)
+
+			y, err := h.At(tt.x)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.y, y)
+		})
+	}
+}
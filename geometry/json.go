@@ -2,6 +2,7 @@ package geometry
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -14,6 +15,26 @@ const (
 	KEY_MIN               = "min"
 	KEY_MAX               = "max"
 	KEY_SCHEDULE          = "schedule"
+	KEY_SHAPE             = "shape"
+	KEY_LOG_SHAPE         = "log_shape"
+	KEY_HORIZONTAL        = "horizontal"
+	KEY_STEP              = "step"
+	KEY_CHANNEL           = "channel"
+	KEY_LOG_CHANNEL       = "log_channel"
+	KEY_PITCHFORK         = "pitchfork"
+	KEY_LOG_PITCHFORK     = "log_pitchfork"
+	KEY_FIBONACCI         = "fibonacci"
+	KEY_LOG_FIBONACCI     = "log_fibonacci"
+	KEY_SPLINE            = "spline"
+	KEY_LOG_SPLINE        = "log_spline"
+	KEY_BEZIER            = "bezier"
+	KEY_LOG_BEZIER        = "log_bezier"
+	KEY_ADD               = "add"
+	KEY_SUB               = "sub"
+	KEY_MUL               = "mul"
+	KEY_SCALE             = "scale"
+	KEY_WEIGHTED_AVERAGE  = "weighted_average"
+	KEY_CLAMP             = "clamp"
 )
 
 // plotJSON is a general structure holding plot type and unparse arguments for that type
@@ -28,6 +49,51 @@ type linePlotJSON struct {
 	ExtendLeft, ExtendRight bool
 }
 
+// shapePlotJSON is a structure holding arguments for Shape, LogShape and Bezier
+type shapePlotJSON struct {
+	Points                  []Point
+	ExtendLeft, ExtendRight bool
+}
+
+// splinePlotJSON is a structure holding arguments for Spline
+type splinePlotJSON struct {
+	Points                  []Point
+	Kind                    SplineKind
+	ExtendLeft, ExtendRight bool
+}
+
+// horizontalPlotJSON is a structure holding arguments for Horizontal
+type horizontalPlotJSON struct {
+	Price                 float64
+	LeftLimit, RightLimit time.Time
+}
+
+// stepPlotJSON is a structure holding arguments for Step
+type stepPlotJSON struct {
+	Points     []Point
+	RightLimit time.Time
+}
+
+// channelPlotJSON is a structure holding arguments for Channel, P0 and P1 are the points of the base line,
+// the parallel line goes through P2
+type channelPlotJSON struct {
+	P0, P1, P2              Point
+	Rail                    Rail
+	ExtendLeft, ExtendRight bool
+}
+
+// pitchforkPlotJSON is a structure holding arguments for Pitchfork
+type pitchforkPlotJSON struct {
+	P0, P1, P2 Point
+	Rail       Rail
+}
+
+// fibonacciPlotJSON is a structure holding arguments for Fibonacci
+type fibonacciPlotJSON struct {
+	Low, High plotJSON
+	Ratio     float64
+}
+
 // oggsetPlotJSON is a structure holding arguments for AbsoluteOffset and PercentageOffset
 type offsetPlotJSON struct {
 	Value float64
@@ -45,6 +111,38 @@ type minMaxPlotJSON struct {
 	Plots []plotJSON
 }
 
+// combinePlotJSON is a structure holding arguments for Add and Mul
+type combinePlotJSON struct {
+	Plots    []plotJSON
+	Validity Validity
+}
+
+// subPlotJSON is a structure holding arguments for Sub
+type subPlotJSON struct {
+	Minuend, Subtrahend plotJSON
+	Validity            Validity
+}
+
+// scalePlotJSON is a structure holding arguments for Scale
+type scalePlotJSON struct {
+	Factor float64
+	Plot   plotJSON
+}
+
+// weightedAveragePlotJSON is a structure holding arguments for WeightedAverage
+type weightedAveragePlotJSON struct {
+	Plots    []plotJSON
+	Weights  []float64
+	Validity Validity
+}
+
+// clampPlotJSON is a structure holding arguments for Clamp, a missing bound is not applied
+type clampPlotJSON struct {
+	Plot        plotJSON
+	Floor, Ceil *plotJSON `json:",omitempty"`
+	Validity    Validity
+}
+
 func FromJSON(data []byte) (Plot, error) {
 	pj := plotJSON{}
 	if err := json.Unmarshal(data, &pj); err != nil {
@@ -54,6 +152,16 @@ func FromJSON(data []byte) (Plot, error) {
 	return parsePlot(pj)
 }
 
+// ToJSON serializes the plot into the format accepted by FromJSON
+func ToJSON(p Plot) ([]byte, error) {
+	pj, err := toPlotJSON(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(pj)
+}
+
 func parsePlot(pj plotJSON) (Plot, error) {
 	args := pj.Args
 
@@ -72,6 +180,105 @@ func parsePlot(pj plotJSON) (Plot, error) {
 		}
 
 		return NewLogLine(lineJSON.P0, lineJSON.P1, lineJSON.ExtendLeft, lineJSON.ExtendRight)
+	case KEY_SHAPE:
+		shapeJSON := shapePlotJSON{}
+		if err := json.Unmarshal(args, &shapeJSON); err != nil {
+			return nil, err
+		}
+
+		return NewShape(shapeJSON.Points, shapeJSON.ExtendLeft, shapeJSON.ExtendRight)
+	case KEY_LOG_SHAPE:
+		shapeJSON := shapePlotJSON{}
+		if err := json.Unmarshal(args, &shapeJSON); err != nil {
+			return nil, err
+		}
+
+		return NewLogShape(shapeJSON.Points, shapeJSON.ExtendLeft, shapeJSON.ExtendRight)
+	case KEY_SPLINE, KEY_LOG_SPLINE:
+		splineJSON := splinePlotJSON{}
+		if err := json.Unmarshal(args, &splineJSON); err != nil {
+			return nil, err
+		}
+
+		if pj.Type == KEY_LOG_SPLINE {
+			return NewLogSpline(splineJSON.Points, splineJSON.Kind, splineJSON.ExtendLeft, splineJSON.ExtendRight)
+		}
+
+		return NewSpline(splineJSON.Points, splineJSON.Kind, splineJSON.ExtendLeft, splineJSON.ExtendRight)
+	case KEY_BEZIER, KEY_LOG_BEZIER:
+		shapeJSON := shapePlotJSON{}
+		if err := json.Unmarshal(args, &shapeJSON); err != nil {
+			return nil, err
+		}
+
+		if pj.Type == KEY_LOG_BEZIER {
+			return NewLogBezier(shapeJSON.Points, shapeJSON.ExtendLeft, shapeJSON.ExtendRight)
+		}
+
+		return NewBezier(shapeJSON.Points, shapeJSON.ExtendLeft, shapeJSON.ExtendRight)
+	case KEY_HORIZONTAL:
+		horizontalJSON := horizontalPlotJSON{}
+		if err := json.Unmarshal(args, &horizontalJSON); err != nil {
+			return nil, err
+		}
+
+		return NewHorizontal(horizontalJSON.Price, horizontalJSON.LeftLimit, horizontalJSON.RightLimit)
+	case KEY_STEP:
+		stepJSON := stepPlotJSON{}
+		if err := json.Unmarshal(args, &stepJSON); err != nil {
+			return nil, err
+		}
+
+		return NewStep(stepJSON.Points, stepJSON.RightLimit)
+	case KEY_CHANNEL:
+		channelJSON := channelPlotJSON{}
+		if err := json.Unmarshal(args, &channelJSON); err != nil {
+			return nil, err
+		}
+
+		return NewChannel(channelJSON.P0, channelJSON.P1, channelJSON.P2, channelJSON.Rail, channelJSON.ExtendLeft, channelJSON.ExtendRight)
+	case KEY_LOG_CHANNEL:
+		channelJSON := channelPlotJSON{}
+		if err := json.Unmarshal(args, &channelJSON); err != nil {
+			return nil, err
+		}
+
+		return NewLogChannel(channelJSON.P0, channelJSON.P1, channelJSON.P2, channelJSON.Rail, channelJSON.ExtendLeft, channelJSON.ExtendRight)
+	case KEY_PITCHFORK:
+		pitchforkJSON := pitchforkPlotJSON{}
+		if err := json.Unmarshal(args, &pitchforkJSON); err != nil {
+			return nil, err
+		}
+
+		return NewPitchfork(pitchforkJSON.P0, pitchforkJSON.P1, pitchforkJSON.P2, pitchforkJSON.Rail)
+	case KEY_LOG_PITCHFORK:
+		pitchforkJSON := pitchforkPlotJSON{}
+		if err := json.Unmarshal(args, &pitchforkJSON); err != nil {
+			return nil, err
+		}
+
+		return NewLogPitchfork(pitchforkJSON.P0, pitchforkJSON.P1, pitchforkJSON.P2, pitchforkJSON.Rail)
+	case KEY_FIBONACCI, KEY_LOG_FIBONACCI:
+		fibonacciJSON := fibonacciPlotJSON{}
+		if err := json.Unmarshal(args, &fibonacciJSON); err != nil {
+			return nil, err
+		}
+
+		low, err := parsePlot(fibonacciJSON.Low)
+		if err != nil {
+			return nil, err
+		}
+
+		high, err := parsePlot(fibonacciJSON.High)
+		if err != nil {
+			return nil, err
+		}
+
+		if pj.Type == KEY_LOG_FIBONACCI {
+			return NewLogFibonacci(low, high, fibonacciJSON.Ratio), nil
+		}
+
+		return NewFibonacci(low, high, fibonacciJSON.Ratio), nil
 	case KEY_ABSOLUTE_OFFSET:
 		offsetJSON := offsetPlotJSON{}
 		if err := json.Unmarshal(args, &offsetJSON); err != nil {
@@ -108,41 +315,378 @@ func parsePlot(pj plotJSON) (Plot, error) {
 		}
 
 		return NewSchedule(scheduleJSON.Since, scheduleJSON.Until, plotToSchedule), nil
-	case KEY_MIN:
-		minmaxJSON := minMaxPlotJSON{}
-		if err := json.Unmarshal(args, &minmaxJSON); err != nil {
+	case KEY_ADD, KEY_MUL:
+		combineJSON := combinePlotJSON{}
+		if err := json.Unmarshal(args, &combineJSON); err != nil {
 			return nil, err
 		}
 
-		plotsToMin := []Plot{}
-		for _, pjMin := range minmaxJSON.Plots {
-			plotToMin, err := parsePlot(pjMin)
-			if err != nil {
+		plots, err := parsePlots(combineJSON.Plots)
+		if err != nil {
+			return nil, err
+		}
+
+		if pj.Type == KEY_MUL {
+			return NewMul(plots, combineJSON.Validity)
+		}
+
+		return NewAdd(plots, combineJSON.Validity)
+	case KEY_SUB:
+		subJSON := subPlotJSON{}
+		if err := json.Unmarshal(args, &subJSON); err != nil {
+			return nil, err
+		}
+
+		minuend, err := parsePlot(subJSON.Minuend)
+		if err != nil {
+			return nil, err
+		}
+
+		subtrahend, err := parsePlot(subJSON.Subtrahend)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewSub(minuend, subtrahend, subJSON.Validity)
+	case KEY_SCALE:
+		scaleJSON := scalePlotJSON{}
+		if err := json.Unmarshal(args, &scaleJSON); err != nil {
+			return nil, err
+		}
+
+		plotToScale, err := parsePlot(scaleJSON.Plot)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewScale(plotToScale, scaleJSON.Factor), nil
+	case KEY_WEIGHTED_AVERAGE:
+		averageJSON := weightedAveragePlotJSON{}
+		if err := json.Unmarshal(args, &averageJSON); err != nil {
+			return nil, err
+		}
+
+		plots, err := parsePlots(averageJSON.Plots)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewWeightedAverage(plots, averageJSON.Weights, averageJSON.Validity)
+	case KEY_CLAMP:
+		clampJSON := clampPlotJSON{}
+		if err := json.Unmarshal(args, &clampJSON); err != nil {
+			return nil, err
+		}
+
+		plotToClamp, err := parsePlot(clampJSON.Plot)
+		if err != nil {
+			return nil, err
+		}
+
+		var floor, ceil Plot
+
+		if clampJSON.Floor != nil {
+			if floor, err = parsePlot(*clampJSON.Floor); err != nil {
 				return nil, err
 			}
+		}
 
-			plotsToMin = append(plotsToMin, plotToMin)
+		if clampJSON.Ceil != nil {
+			if ceil, err = parsePlot(*clampJSON.Ceil); err != nil {
+				return nil, err
+			}
+		}
+
+		return NewClamp(plotToClamp, floor, ceil, clampJSON.Validity)
+	case KEY_MIN:
+		minmaxJSON := minMaxPlotJSON{}
+		if err := json.Unmarshal(args, &minmaxJSON); err != nil {
+			return nil, err
 		}
 
-		return NewMin(plotsToMin)
+		plots, err := parsePlots(minmaxJSON.Plots)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewMin(plots)
 	case KEY_MAX:
 		minmaxJSON := minMaxPlotJSON{}
 		if err := json.Unmarshal(args, &minmaxJSON); err != nil {
 			return nil, err
 		}
 
-		plotsToMin := []Plot{}
-		for _, pjMin := range minmaxJSON.Plots {
-			plotToMin, err := parsePlot(pjMin)
+		plots, err := parsePlots(minmaxJSON.Plots)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewMax(plots)
+	}
+
+	return nil, fmt.Errorf("unknown plot name %s", pj.Type)
+}
+
+func parsePlots(pjs []plotJSON) ([]Plot, error) {
+	plots := []Plot{}
+
+	for _, pj := range pjs {
+		p, err := parsePlot(pj)
+		if err != nil {
+			return nil, err
+		}
+
+		plots = append(plots, p)
+	}
+
+	return plots, nil
+}
+
+func toPlotJSON(p Plot) (plotJSON, error) {
+	switch v := p.(type) {
+	case *Line:
+		p0, p1 := v.points()
+		return newPlotJSON(KEY_LINE, linePlotJSON{
+			P0:          p0,
+			P1:          p1,
+			ExtendLeft:  v.LeftLimit.IsZero(),
+			ExtendRight: v.RightLimit.IsZero(),
+		})
+	case *LogLine:
+		p0, p1 := v.points()
+		return newPlotJSON(KEY_LOG_LINE, linePlotJSON{
+			P0:          p0,
+			P1:          p1,
+			ExtendLeft:  v.LeftLimit.IsZero(),
+			ExtendRight: v.RightLimit.IsZero(),
+		})
+	case *Shape:
+		if len(v.Lines) == 0 {
+			return plotJSON{}, errors.New("error serializing shape: no lines")
+		}
+
+		return newPlotJSON(KEY_SHAPE, shapePlotJSON{
+			Points:      v.points(),
+			ExtendLeft:  v.Lines[0].LeftLimit.IsZero(),
+			ExtendRight: v.Lines[len(v.Lines)-1].RightLimit.IsZero(),
+		})
+	case *LogShape:
+		if len(v.Lines) == 0 {
+			return plotJSON{}, errors.New("error serializing log shape: no lines")
+		}
+
+		return newPlotJSON(KEY_LOG_SHAPE, shapePlotJSON{
+			Points:      v.points(),
+			ExtendLeft:  v.Lines[0].LeftLimit.IsZero(),
+			ExtendRight: v.Lines[len(v.Lines)-1].RightLimit.IsZero(),
+		})
+	case *Spline:
+		key := KEY_SPLINE
+		if v.Log {
+			key = KEY_LOG_SPLINE
+		}
+
+		return newPlotJSON(key, splinePlotJSON{
+			Points:      v.Points,
+			Kind:        v.Kind,
+			ExtendLeft:  v.ExtendLeft,
+			ExtendRight: v.ExtendRight,
+		})
+	case *Bezier:
+		key := KEY_BEZIER
+		if v.Log {
+			key = KEY_LOG_BEZIER
+		}
+
+		return newPlotJSON(key, shapePlotJSON{
+			Points:      v.Points,
+			ExtendLeft:  v.ExtendLeft,
+			ExtendRight: v.ExtendRight,
+		})
+	case *Horizontal:
+		return newPlotJSON(KEY_HORIZONTAL, horizontalPlotJSON{Price: v.Price, LeftLimit: v.LeftLimit, RightLimit: v.RightLimit})
+	case *Step:
+		return newPlotJSON(KEY_STEP, stepPlotJSON{Points: v.Points, RightLimit: v.RightLimit})
+	case *Channel:
+		switch base := v.Base.(type) {
+		case *Line:
+			p0, p1 := base.points()
+			return newPlotJSON(KEY_CHANNEL, channelPlotJSON{
+				P0:          p0,
+				P1:          p1,
+				P2:          v.through,
+				Rail:        v.Rail,
+				ExtendLeft:  base.LeftLimit.IsZero(),
+				ExtendRight: base.RightLimit.IsZero(),
+			})
+		case *LogLine:
+			p0, p1 := base.points()
+			return newPlotJSON(KEY_LOG_CHANNEL, channelPlotJSON{
+				P0:          p0,
+				P1:          p1,
+				P2:          v.through,
+				Rail:        v.Rail,
+				ExtendLeft:  base.LeftLimit.IsZero(),
+				ExtendRight: base.RightLimit.IsZero(),
+			})
+		}
+
+		return plotJSON{}, fmt.Errorf("unknown channel base type %T", v.Base)
+	case *Pitchfork:
+		args := pitchforkPlotJSON{P0: v.pivots[0], P1: v.pivots[1], P2: v.pivots[2], Rail: v.Rail}
+
+		switch v.Median.(type) {
+		case *Line:
+			return newPlotJSON(KEY_PITCHFORK, args)
+		case *LogLine:
+			return newPlotJSON(KEY_LOG_PITCHFORK, args)
+		}
+
+		return plotJSON{}, fmt.Errorf("unknown pitchfork median type %T", v.Median)
+	case *Fibonacci:
+		low, err := toPlotJSON(v.Low)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		high, err := toPlotJSON(v.High)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		key := KEY_FIBONACCI
+		if v.Log {
+			key = KEY_LOG_FIBONACCI
+		}
+
+		return newPlotJSON(key, fibonacciPlotJSON{Low: low, High: high, Ratio: v.Ratio})
+	case *OffsetPlot:
+		plotToOffset, err := toPlotJSON(v.Plot)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		switch offset := v.Offsetter.(type) {
+		case *AbsoluteOffset:
+			return newPlotJSON(KEY_ABSOLUTE_OFFSET, offsetPlotJSON{Value: offset.Value, Plot: plotToOffset})
+		case *PercentageOffset:
+			return newPlotJSON(KEY_PERCENTAGE_OFFSET, offsetPlotJSON{Value: offset.Percentage, Plot: plotToOffset})
+		}
+
+		return plotJSON{}, fmt.Errorf("unknown offset type %T", v.Offsetter)
+	case *Schedule:
+		plotToSchedule, err := toPlotJSON(v.Plot)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		return newPlotJSON(KEY_SCHEDULE, schedulePlotJSON{Since: v.Since, Until: v.Until, Plot: plotToSchedule})
+	case *Add:
+		plots, err := toPlotJSONs(v.Plots)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		return newPlotJSON(KEY_ADD, combinePlotJSON{Plots: plots, Validity: v.Validity})
+	case *Mul:
+		plots, err := toPlotJSONs(v.Plots)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		return newPlotJSON(KEY_MUL, combinePlotJSON{Plots: plots, Validity: v.Validity})
+	case *Sub:
+		minuend, err := toPlotJSON(v.Minuend)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		subtrahend, err := toPlotJSON(v.Subtrahend)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		return newPlotJSON(KEY_SUB, subPlotJSON{Minuend: minuend, Subtrahend: subtrahend, Validity: v.Validity})
+	case *Scale:
+		plotToScale, err := toPlotJSON(v.Plot)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		return newPlotJSON(KEY_SCALE, scalePlotJSON{Factor: v.Factor, Plot: plotToScale})
+	case *WeightedAverage:
+		plots, err := toPlotJSONs(v.Plots)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		return newPlotJSON(KEY_WEIGHTED_AVERAGE, weightedAveragePlotJSON{Plots: plots, Weights: v.Weights, Validity: v.Validity})
+	case *Clamp:
+		plotToClamp, err := toPlotJSON(v.Plot)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		args := clampPlotJSON{Plot: plotToClamp, Validity: v.Validity}
+
+		if v.Floor != nil {
+			floor, err := toPlotJSON(v.Floor)
 			if err != nil {
-				return nil, err
+				return plotJSON{}, err
+			}
+
+			args.Floor = &floor
+		}
+
+		if v.Ceil != nil {
+			ceil, err := toPlotJSON(v.Ceil)
+			if err != nil {
+				return plotJSON{}, err
 			}
 
-			plotsToMin = append(plotsToMin, plotToMin)
+			args.Ceil = &ceil
+		}
+
+		return newPlotJSON(KEY_CLAMP, args)
+	case *Min:
+		plots, err := toPlotJSONs(v.Plots)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		return newPlotJSON(KEY_MIN, minMaxPlotJSON{Plots: plots})
+	case *Max:
+		plots, err := toPlotJSONs(v.Plots)
+		if err != nil {
+			return plotJSON{}, err
+		}
+
+		return newPlotJSON(KEY_MAX, minMaxPlotJSON{Plots: plots})
+	}
+
+	return plotJSON{}, fmt.Errorf("unknown plot type %T", p)
+}
+
+func toPlotJSONs(plots []Plot) ([]plotJSON, error) {
+	pjs := []plotJSON{}
+
+	for _, p := range plots {
+		pj, err := toPlotJSON(p)
+		if err != nil {
+			return nil, err
 		}
 
-		return NewMax(plotsToMin)
+		pjs = append(pjs, pj)
 	}
 
-	return nil, fmt.Errorf("unknown plot name %s", pj.Type)
+	return pjs, nil
+}
+
+func newPlotJSON(typ string, args any) (plotJSON, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return plotJSON{}, fmt.Errorf("error marshalling %s arguments: %w", typ, err)
+	}
+
+	return plotJSON{Type: typ, Args: data}, nil
 }
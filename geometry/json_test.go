@@ -0,0 +1,104 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustPlot(t *testing.T) func(p geometry.Plot, err error) geometry.Plot {
+	return func(p geometry.Plot, err error) geometry.Plot {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return p
+	}
+}
+
+func TestToJSON_RoundTrip(t *testing.T) {
+	must := mustPlot(t)
+
+	p0 := geometry.Point{Date: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC), Price: 100}
+	p1 := geometry.Point{Date: time.Date(2023, 1, 16, 0, 0, 0, 0, time.UTC), Price: 110.5}
+	p2 := geometry.Point{Date: time.Date(2023, 1, 17, 0, 0, 0, 0, time.UTC), Price: 105.25}
+
+	line := must(geometry.NewLine(p0, p1, true, false))
+	logLine := must(geometry.NewLogLine(p0, p1, false, true))
+
+	tests := []struct {
+		name string
+		plot geometry.Plot
+	}{
+		{"line", line},
+		{"log line", logLine},
+		{"shape", must(geometry.NewShape([]geometry.Point{p0, p1, p2}, true, false))},
+		{"log shape", must(geometry.NewLogShape([]geometry.Point{p2, p0, p1}, false, false))},
+		{"horizontal", must(geometry.NewHorizontal(100, p0.Date, time.Time{}))},
+		{"step", must(geometry.NewStep([]geometry.Point{p1, p0}, p2.Date))},
+		{"channel", must(geometry.NewChannel(p0, p1, p2, geometry.RailLower, false, true))},
+		{"log channel", must(geometry.NewLogChannel(p0, p2, p1, geometry.RailMid, true, false))},
+		{"pitchfork", must(geometry.NewPitchfork(p0, p1, p2, geometry.RailUpper))},
+		{"log pitchfork", must(geometry.NewLogPitchfork(p0, p2, p1, geometry.RailMid))},
+		{"fibonacci", geometry.NewFibonacci(line, logLine, 0.618)},
+		{"log fibonacci", must(geometry.NewFibonacciFromPoints(p0, p1, 1.272, true))},
+		{"spline", must(geometry.NewSpline([]geometry.Point{p0, p1, p2}, geometry.SplineMonotone, true, false))},
+		{"log spline", must(geometry.NewLogSpline([]geometry.Point{p2, p0, p1}, geometry.SplineCatmullRom, false, true))},
+		{"bezier", must(geometry.NewBezier([]geometry.Point{p0, p1, p2}, false, false))},
+		{"log bezier", must(geometry.NewLogBezier([]geometry.Point{p0, p1, p2}, true, true))},
+		{"add", must(geometry.NewAdd([]geometry.Plot{line, logLine}, geometry.ValidAny))},
+		{"sub", must(geometry.NewSub(line, logLine, geometry.ValidAll))},
+		{"mul", must(geometry.NewMul([]geometry.Plot{line, geometry.NewScale(logLine, -1)}, ""))},
+		{"scale", geometry.NewScale(logLine, 0.5)},
+		{"weighted average", must(geometry.NewWeightedAverage([]geometry.Plot{line, logLine}, []float64{1, 3}, geometry.ValidAny))},
+		{"clamp", must(geometry.NewClamp(logLine, line, nil, geometry.ValidAny))},
+		{"clamp - both bounds", must(geometry.NewClamp(logLine, line, geometry.NewScale(line, 2), geometry.ValidAll))},
+		{"absolute offset", geometry.NewOffsetPlot(line, geometry.NewAbsoluteOffset(-5))},
+		{"percentage offset", geometry.NewOffsetPlot(logLine, geometry.NewPercentageOffset(0.01))},
+		{"schedule", geometry.NewSchedule(p0.Date, p2.Date, line)},
+		{"min", must(geometry.NewMin([]geometry.Plot{line, logLine}))},
+		{"max", must(geometry.NewMax([]geometry.Plot{line, geometry.NewSchedule(time.Time{}, p1.Date, logLine)}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := geometry.ToJSON(tt.plot)
+			assert.NoError(t, err)
+
+			got, err := geometry.FromJSON(data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.plot, got)
+
+			again, err := geometry.ToJSON(got)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(data), string(again))
+		})
+	}
+}
+
+func TestToJSON_LiteralLine(t *testing.T) {
+	line := &geometry.Line{A: 2, B: 10}
+
+	data, err := geometry.ToJSON(line)
+	assert.NoError(t, err)
+
+	got, err := geometry.FromJSON(data)
+	assert.NoError(t, err)
+
+	for _, sec := range []int64{-3600, 0, 1800, 7200} {
+		want, err := line.At(time.Unix(sec, 0))
+		assert.NoError(t, err)
+
+		y, err := got.At(time.Unix(sec, 0))
+		assert.NoError(t, err)
+		assert.InDelta(t, want, y, 1e-9)
+	}
+}
+
+func TestToJSON_UnknownPlot(t *testing.T) {
+	_, err := geometry.ToJSON(&neverValid{})
+	assert.Error(t, err)
+}
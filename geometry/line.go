@@ -14,6 +14,8 @@ type Point struct {
 type Line struct {
 	A, B                  float64
 	LeftLimit, RightLimit time.Time
+	// p0, p1 are the points the line was created from, they are kept to serialize the line without loss of precision
+	p0, p1 Point
 }
 
 func NewLine(p0, p1 Point, extendLeft, extendRight bool) (*Line, error) {
@@ -32,8 +34,10 @@ func NewLine(p0, p1 Point, extendLeft, extendRight bool) (*Line, error) {
 	b := p0.Price - (a * p0DateFloat)
 
 	l := &Line{
-		A: a,
-		B: b,
+		A:  a,
+		B:  b,
+		p0: p0,
+		p1: p1,
 	}
 
 	if !extendLeft {
@@ -59,6 +63,8 @@ func (l *Line) At(date time.Time) (float64, error) {
 type LogLine struct {
 	M, K, Xoffset         float64
 	LeftLimit, RightLimit time.Time
+	// p0, p1 are the points the line was created from, they are kept to serialize the line without loss of precision
+	p0, p1 Point
 }
 
 func NewLogLine(p0, p1 Point, extendLeft, extendRight bool) (*LogLine, error) {
@@ -83,6 +89,8 @@ func NewLogLine(p0, p1 Point, extendLeft, extendRight bool) (*LogLine, error) {
 		M:       m,
 		K:       y0,
 		Xoffset: xOffset,
+		p0:      p0,
+		p1:      p1,
 	}
 
 	if !extendLeft {
@@ -105,6 +113,43 @@ func (l *LogLine) At(date time.Time) (float64, error) {
 	return l.K * math.Pow(10, l.M*x), nil
 }
 
+// points returns two points that lie on the line, if the line was not created with NewLine they are derived from its limits
+func (l *Line) points() (Point, Point) {
+	if !l.p0.Date.Equal(l.p1.Date) {
+		return l.p0, l.p1
+	}
+
+	d0, d1 := limitDates(l.LeftLimit, l.RightLimit)
+
+	return Point{Date: d0, Price: l.A*timeToFloat64(d0) + l.B}, Point{Date: d1, Price: l.A*timeToFloat64(d1) + l.B}
+}
+
+// points returns two points that lie on the line, if the line was not created with NewLogLine they are derived from its limits
+func (l *LogLine) points() (Point, Point) {
+	if !l.p0.Date.Equal(l.p1.Date) {
+		return l.p0, l.p1
+	}
+
+	d0, d1 := limitDates(l.LeftLimit, l.RightLimit)
+	at := func(d time.Time) float64 { return l.K * math.Pow(10, l.M*(timeToFloat64(d)-l.Xoffset)) }
+
+	return Point{Date: d0, Price: at(d0)}, Point{Date: d1, Price: at(d1)}
+}
+
+// limitDates returns two distinct dates to sample a line at, limits are used when they are set
+func limitDates(leftLimit, rightLimit time.Time) (time.Time, time.Time) {
+	switch {
+	case !leftLimit.IsZero() && !rightLimit.IsZero():
+		return leftLimit, rightLimit
+	case !leftLimit.IsZero():
+		return leftLimit, leftLimit.Add(time.Hour)
+	case !rightLimit.IsZero():
+		return rightLimit.Add(-time.Hour), rightLimit
+	}
+
+	return time.Unix(0, 0).UTC(), time.Unix(3600, 0).UTC()
+}
+
 // lineInRange performs a [leftLimit, rightLimit) check
 func lineInRange(t, leftLimit, rightLimit time.Time) bool {
 	return (!t.Before(leftLimit) || leftLimit.IsZero()) && (t.Before(rightLimit) || rightLimit.IsZero())
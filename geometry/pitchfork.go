@@ -0,0 +1,86 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Pitchfork is an Andrews' pitchfork drawn from three pivots, At returns the price of the selected rail.
+// The median line goes from the first pivot through the midpoint of the other two, the tines are parallel to it
+// and go through the second and the third pivot. The upper and lower rails are the higher and the lower tine,
+// the mid rail is the median line. The pitchfork starts at the first pivot and extends indefinitely to the right.
+type Pitchfork struct {
+	Median Plot
+	Tines  [2]Plot
+	Rail   Rail
+	// pivots are the points the pitchfork was drawn from, they are kept to serialize the pitchfork
+	pivots [3]Point
+}
+
+// NewPitchfork creates a pitchfork using Lines, p0 has to be before p1 and p2
+func NewPitchfork(p0, p1, p2 Point, rail Rail) (*Pitchfork, error) {
+	if err := validatePivots(p0, p1, p2, rail); err != nil {
+		return nil, err
+	}
+
+	mid := Point{Date: midDate(p1.Date, p2.Date), Price: (p1.Price + p2.Price) / 2}
+
+	median, err := NewLine(p0, mid, false, true)
+	if err != nil {
+		return nil, fmt.Errorf("error creating pitchfork median: %w", err)
+	}
+
+	return &Pitchfork{
+		Median: median,
+		Tines:  [2]Plot{parallelLine(median, p1), parallelLine(median, p2)},
+		Rail:   rail,
+		pivots: [3]Point{p0, p1, p2},
+	}, nil
+}
+
+// NewLogPitchfork creates a pitchfork using LogLines, the midpoint of p1 and p2 is their geometric mean
+func NewLogPitchfork(p0, p1, p2 Point, rail Rail) (*Pitchfork, error) {
+	if err := validatePivots(p0, p1, p2, rail); err != nil {
+		return nil, err
+	}
+
+	mid := Point{Date: midDate(p1.Date, p2.Date), Price: math.Sqrt(p1.Price * p2.Price)}
+
+	median, err := NewLogLine(p0, mid, false, true)
+	if err != nil {
+		return nil, fmt.Errorf("error creating pitchfork median: %w", err)
+	}
+
+	return &Pitchfork{
+		Median: median,
+		Tines:  [2]Plot{parallelLogLine(median, p1), parallelLogLine(median, p2)},
+		Rail:   rail,
+		pivots: [3]Point{p0, p1, p2},
+	}, nil
+}
+
+func (p *Pitchfork) At(t time.Time) (float64, error) {
+	if p.Rail == RailMid {
+		return p.Median.At(t)
+	}
+
+	return railAt(p.Rail, false, p.Tines[0], p.Tines[1], t)
+}
+
+func validatePivots(p0, p1, p2 Point, rail Rail) error {
+	if err := rail.Validate(); err != nil {
+		return fmt.Errorf("error creating pitchfork: %w", err)
+	}
+
+	if !p0.Date.Before(p1.Date) || !p0.Date.Before(p2.Date) {
+		return errors.New("error creating pitchfork: the first pivot has to be before the other two")
+	}
+
+	return nil
+}
+
+func midDate(d0, d1 time.Time) time.Time {
+	return d0.Add(d1.Sub(d0) / 2)
+}
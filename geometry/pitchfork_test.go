@@ -0,0 +1,113 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPitchfork(t *testing.T) {
+	tests := []struct {
+		name       string
+		p0, p1, p2 geometry.Point
+		rail       geometry.Rail
+		expectErr  bool
+	}{
+		{
+			name: "valid",
+			p0:   geometry.Point{time.Unix(0, 0), 1},
+			p1:   geometry.Point{time.Unix(10, 0), 20},
+			p2:   geometry.Point{time.Unix(20, 0), 1},
+			rail: geometry.RailMid,
+		},
+		{
+			name:      "first pivot not first",
+			p0:        geometry.Point{time.Unix(10, 0), 1},
+			p1:        geometry.Point{time.Unix(10, 0), 20},
+			p2:        geometry.Point{time.Unix(20, 0), 1},
+			rail:      geometry.RailMid,
+			expectErr: true,
+		},
+		{
+			name:      "unknown rail",
+			p0:        geometry.Point{time.Unix(0, 0), 1},
+			p1:        geometry.Point{time.Unix(10, 0), 20},
+			p2:        geometry.Point{time.Unix(20, 0), 1},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := geometry.NewPitchfork(tt.p0, tt.p1, tt.p2, tt.rail)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, p == nil)
+
+			lp, err := geometry.NewLogPitchfork(tt.p0, tt.p1, tt.p2, tt.rail)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, lp == nil)
+		})
+	}
+}
+
+func TestPitchfork_At(t *testing.T) {
+	// the median line goes from (0, 0) through (15, 10), the tines are 40/3 above and below it
+	p0 := geometry.Point{time.Unix(0, 0), 0}
+	p1 := geometry.Point{time.Unix(10, 0), 20}
+	p2 := geometry.Point{time.Unix(20, 0), 0}
+
+	tests := []struct {
+		name string
+		rail geometry.Rail
+		x    time.Time
+		y    float64
+		err  error
+	}{
+		{name: "mid", rail: geometry.RailMid, x: time.Unix(30, 0), y: 20},
+		{name: "upper", rail: geometry.RailUpper, x: time.Unix(30, 0), y: 20 + 40.0/3},
+		{name: "lower", rail: geometry.RailLower, x: time.Unix(30, 0), y: 20 - 40.0/3},
+		{name: "upper - through pivot", rail: geometry.RailUpper, x: time.Unix(10, 0), y: 20},
+		{name: "lower - through pivot", rail: geometry.RailLower, x: time.Unix(20, 0), y: 0},
+		{name: "before first pivot", rail: geometry.RailMid, x: time.Unix(-1, 0), err: geometry.ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := geometry.NewPitchfork(p0, p1, p2, tt.rail)
+			assert.NoError(t, err)
+
+			y, err := p.At(tt.x)
+			assert.ErrorIs(t, err, tt.err)
+			assert.InDelta(t, tt.y, y, 1e-9)
+		})
+	}
+}
+
+func TestLogPitchfork_At(t *testing.T) {
+	// the median line goes from (0, 1) through (15, 10) and reaches 100 at 30
+	p0 := geometry.Point{time.Unix(0, 0), 1}
+	p1 := geometry.Point{time.Unix(10, 0), 100}
+	p2 := geometry.Point{time.Unix(20, 0), 1}
+
+	tests := []struct {
+		rail geometry.Rail
+		y    float64
+	}{
+		{rail: geometry.RailMid, y: 100},
+		{rail: geometry.RailUpper, y: 2154.4346900318824},
+		{rail: geometry.RailLower, y: 4.641588833612778},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.rail), func(t *testing.T) {
+			p, err := geometry.NewLogPitchfork(p0, p1, p2, tt.rail)
+			assert.NoError(t, err)
+
+			y, err := p.At(time.Unix(30, 0))
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.y, y, 1e-6)
+		})
+	}
+}
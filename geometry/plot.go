@@ -10,7 +10,6 @@ var ErrOutOfRange = errors.New("out of plot's range")
 
 type Plot interface {
 	At(time.Time) (float64, error)
-	// Marshal() ([]byte, error)
 }
 
 func timeToFloat64(t time.Time) float64 {
@@ -11,12 +11,12 @@ type Shape struct {
 	Lines []*Line
 }
 
-// NewShape is a Shape constructor, it accepts slice of points which are then sorted by time and connected using lines.
+// NewShape is a Shape constructor, it accepts slice of at least 2 points which are then sorted by time and connected using lines.
 // If extendLeft is true then the first line extends indefinitely to the left.
 // if extendRight is true then the last line extends indefinitely to the right.
 func NewShape(points []Point, extendLeft, extendRight bool) (*Shape, error) {
-	if len(points) < 3 {
-		return nil, fmt.Errorf("at least 3 points are required to create a shape, got: %d", len(points))
+	if len(points) < 2 {
+		return nil, fmt.Errorf("at least 2 points are required to create a shape, got: %d", len(points))
 	}
 	points = sortPoints(points...)
 
@@ -25,7 +25,7 @@ func NewShape(points []Point, extendLeft, extendRight bool) (*Shape, error) {
 	for i := 0; i < len(points)-1; i++ {
 		j := i + 1
 
-		line, err := NewLine(points[i], points[j], i == 0 && extendLeft, i == len(points)-1 && extendRight)
+		line, err := NewLine(points[i], points[j], i == 0 && extendLeft, i == len(points)-2 && extendRight)
 		if err != nil {
 			return nil, fmt.Errorf("error creating line between points %d and %d: %w", i, j, err)
 		}
@@ -53,15 +53,31 @@ func (s *Shape) At(t time.Time) (float64, error) {
 	return 0, ErrOutOfRange
 }
 
+// points returns the points the shape was created from
+func (s *Shape) points() []Point {
+	points := []Point{}
+
+	for i, line := range s.Lines {
+		p0, p1 := line.points()
+		if i == 0 {
+			points = append(points, p0)
+		}
+
+		points = append(points, p1)
+	}
+
+	return points
+}
+
 // LogShape is a Shape that uses LogLines instead of Lines
 type LogShape struct {
 	Lines []*LogLine
 }
 
-// NewShape requires at least 3 points
+// NewLogShape is a LogShape constructor, it accepts the same arguments as NewShape
 func NewLogShape(points []Point, extendLeft, extendRight bool) (*LogShape, error) {
-	if len(points) < 3 {
-		return nil, fmt.Errorf("at least 3 points are required to create a shape, got: %d", len(points))
+	if len(points) < 2 {
+		return nil, fmt.Errorf("at least 2 points are required to create a shape, got: %d", len(points))
 	}
 	points = sortPoints(points...)
 
@@ -70,7 +86,7 @@ func NewLogShape(points []Point, extendLeft, extendRight bool) (*LogShape, error
 	for i := 0; i < len(points)-1; i++ {
 		j := i + 1
 
-		line, err := NewLogLine(points[i], points[j], i == 0 && extendLeft, i == len(points)-1 && extendRight)
+		line, err := NewLogLine(points[i], points[j], i == 0 && extendLeft, i == len(points)-2 && extendRight)
 		if err != nil {
 			return nil, fmt.Errorf("error creating line between points %d and %d: %w", i, j, err)
 		}
@@ -97,3 +113,19 @@ func (s *LogShape) At(t time.Time) (float64, error) {
 
 	return 0, ErrOutOfRange
 }
+
+// points returns the points the shape was created from
+func (s *LogShape) points() []Point {
+	points := []Point{}
+
+	for i, line := range s.Lines {
+		p0, p1 := line.points()
+		if i == 0 {
+			points = append(points, p0)
+		}
+
+		points = append(points, p1)
+	}
+
+	return points
+}
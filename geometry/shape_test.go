@@ -0,0 +1,135 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShape(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []geometry.Point
+		expectErr bool
+	}{
+		{"no points", nil, true},
+		{"1 point", []geometry.Point{{time.Unix(0, 0), 1}}, true},
+		{"2 points", []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}}, false},
+		{"same date", []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(0, 0), 2}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewShape(tt.points, false, false)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, s == nil)
+
+			ls, err := geometry.NewLogShape(tt.points, false, false)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, ls == nil)
+		})
+	}
+}
+
+func TestShape_At(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(20, 0), 10},
+		{time.Unix(0, 0), 0},
+		{time.Unix(10, 0), 20},
+	}
+
+	tests := []struct {
+		name                    string
+		extendLeft, extendRight bool
+		x                       time.Time
+		y                       float64
+		err                     error
+	}{
+		{name: "first point", x: time.Unix(0, 0), y: 0},
+		{name: "first segment", x: time.Unix(5, 0), y: 10},
+		{name: "middle point", x: time.Unix(10, 0), y: 20},
+		{name: "second segment", x: time.Unix(15, 0), y: 15},
+		{name: "left - not extended", x: time.Unix(-10, 0), err: geometry.ErrOutOfRange},
+		{name: "right - not extended", x: time.Unix(30, 0), err: geometry.ErrOutOfRange},
+		{name: "left - extended", extendLeft: true, x: time.Unix(-10, 0), y: -20},
+		{name: "right - extended", extendRight: true, x: time.Unix(30, 0), y: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewShape(points, tt.extendLeft, tt.extendRight)
+			assert.NoError(t, err)
+
+			y, err := s.At(tt.x)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.y, y)
+		})
+	}
+}
+
+func TestLogShape_At(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(0, 0), 1},
+		{time.Unix(2, 0), 100},
+		{time.Unix(4, 0), 1},
+	}
+
+	tests := []struct {
+		name                    string
+		extendLeft, extendRight bool
+		x                       time.Time
+		y                       float64
+		err                     error
+	}{
+		{name: "first segment", x: time.Unix(1, 0), y: 10},
+		{name: "middle point", x: time.Unix(2, 0), y: 100},
+		{name: "second segment", x: time.Unix(3, 0), y: 10},
+		{name: "right - not extended", x: time.Unix(5, 0), err: geometry.ErrOutOfRange},
+		{name: "right - extended", extendRight: true, x: time.Unix(5, 0), y: 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewLogShape(points, tt.extendLeft, tt.extendRight)
+			assert.NoError(t, err)
+
+			y, err := s.At(tt.x)
+			assert.ErrorIs(t, err, tt.err)
+			assert.InDelta(t, tt.y, y, 1e-9)
+		})
+	}
+}
+
+func TestFromJSON_Shape(t *testing.T) {
+	data := []byte(`{
+		"Type": "shape",
+		"Args": {
+			"Points": [
+				{"Date": "2023-01-15T00:00:00Z", "Price": 100},
+				{"Date": "2023-01-16T00:00:00Z", "Price": 120},
+				{"Date": "2023-01-17T00:00:00Z", "Price": 110}
+			],
+			"ExtendRight": true
+		}
+	}`)
+
+	p, err := geometry.FromJSON(data)
+	assert.NoError(t, err)
+	assert.IsType(t, &geometry.Shape{}, p)
+
+	y, err := p.At(time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 110.0, y)
+
+	y, err = p.At(time.Date(2023, 1, 18, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, y)
+
+	_, err = p.At(time.Date(2023, 1, 14, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, geometry.ErrOutOfRange)
+
+	_, err = geometry.FromJSON([]byte(`{"Type": "log_shape", "Args": {"Points": [{"Date": "2023-01-15T00:00:00Z", "Price": 100}]}}`))
+	assert.Error(t, err)
+}
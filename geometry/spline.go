@@ -0,0 +1,221 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// SplineKind decides how the tangents of a Spline are chosen
+type SplineKind string
+
+const (
+	// SplineMonotone is a monotone cubic (Fritsch-Butland) spline, it does not overshoot the points,
+	// the curve is flat at local extremes and monotone between the points
+	SplineMonotone SplineKind = "monotone"
+	// SplineCatmullRom is a Catmull-Rom spline, it's smoother but can overshoot the points
+	SplineCatmullRom SplineKind = "catmull_rom"
+)
+
+func (k SplineKind) Validate() error {
+	switch k {
+	case SplineMonotone, SplineCatmullRom:
+		return nil
+	}
+
+	return fmt.Errorf("unknown spline kind: %s", k)
+}
+
+// Spline is a smooth curve going through all of its points, consecutive points are connected with cubic Hermite segments.
+// The range is [first point, last point), if ExtendLeft or ExtendRight is true the curve extends indefinitely
+// along its tangent at the first or the last point. Log splines are interpolated on semi-logarithmic (x, log10) graph.
+type Spline struct {
+	Points                  []Point
+	Kind                    SplineKind
+	ExtendLeft, ExtendRight bool
+	Log                     bool
+	// xs, ys are the coordinates of the points, ys are log10 of the prices of log splines
+	xs, ys []float64
+	// slopes are the tangents of the curve at the points
+	slopes []float64
+}
+
+// NewSpline is a Spline constructor, it accepts slice of at least 2 points with unique dates which are then sorted by time
+func NewSpline(points []Point, kind SplineKind, extendLeft, extendRight bool) (*Spline, error) {
+	return newSpline(points, kind, extendLeft, extendRight, false)
+}
+
+// NewLogSpline is a Spline constructor for log scale, it accepts the same arguments as NewSpline, prices have to be positive
+func NewLogSpline(points []Point, kind SplineKind, extendLeft, extendRight bool) (*Spline, error) {
+	return newSpline(points, kind, extendLeft, extendRight, true)
+}
+
+func newSpline(points []Point, kind SplineKind, extendLeft, extendRight, log bool) (*Spline, error) {
+	if err := kind.Validate(); err != nil {
+		return nil, fmt.Errorf("error creating spline: %w", err)
+	}
+
+	points, xs, ys, err := curvePoints(points, log)
+	if err != nil {
+		return nil, fmt.Errorf("error creating spline: %w", err)
+	}
+
+	slopes := monotoneSlopes(xs, ys)
+	if kind == SplineCatmullRom {
+		slopes = catmullRomSlopes(xs, ys)
+	}
+
+	return &Spline{
+		Points:      points,
+		Kind:        kind,
+		ExtendLeft:  extendLeft,
+		ExtendRight: extendRight,
+		Log:         log,
+		xs:          xs,
+		ys:          ys,
+		slopes:      slopes,
+	}, nil
+}
+
+func (s *Spline) At(t time.Time) (float64, error) {
+	x := timeToFloat64(t)
+	n := len(s.xs)
+
+	if n < 2 || len(s.ys) != n || len(s.slopes) != n {
+		return 0, errCurveNotPrepared
+	}
+
+	if y, ok, err := extendCurve(x, s.xs, s.ys, s.slopes[0], s.slopes[n-1], s.ExtendLeft, s.ExtendRight); err != nil {
+		return 0, err
+	} else if ok {
+		return curvePrice(y, s.Log), nil
+	}
+
+	// the segment [xs[k], xs[k+1]) containing x
+	k := sort.Search(n, func(i int) bool { return s.xs[i] > x }) - 1
+	h := s.xs[k+1] - s.xs[k]
+	u := (x - s.xs[k]) / h
+
+	h00 := 2*u*u*u - 3*u*u + 1
+	h10 := u*u*u - 2*u*u + u
+	h01 := -2*u*u*u + 3*u*u
+	h11 := u*u*u - u*u
+
+	y := h00*s.ys[k] + h10*h*s.slopes[k] + h01*s.ys[k+1] + h11*h*s.slopes[k+1]
+
+	return curvePrice(y, s.Log), nil
+}
+
+// errCurveNotPrepared is returned by curves that were not created with their constructor e.g. zero values
+var errCurveNotPrepared = errors.New("curve has less than 2 prepared points, it has to be created with its constructor")
+
+// curvePoints sorts the points and returns their coordinates, it requires at least 2 points with unique dates
+// and positive prices on log scale
+func curvePoints(points []Point, log bool) ([]Point, []float64, []float64, error) {
+	if len(points) < 2 {
+		return nil, nil, nil, fmt.Errorf("at least 2 points are required, got: %d", len(points))
+	}
+
+	points = sortPoints(append([]Point{}, points...)...)
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+
+	for i, p := range points {
+		if i > 0 && p.Date.Equal(points[i-1].Date) {
+			return nil, nil, nil, fmt.Errorf("points %d and %d have the same date", i-1, i)
+		}
+
+		xs[i] = timeToFloat64(p.Date)
+		ys[i] = p.Price
+
+		if log {
+			if p.Price <= 0 {
+				return nil, nil, nil, fmt.Errorf("point %d has non-positive price %f", i, p.Price)
+			}
+
+			ys[i] = math.Log10(p.Price)
+		}
+	}
+
+	return points, xs, ys, nil
+}
+
+// extendCurve returns the value of the curve outside of [xs[0], xs[n-1]), it's a line along the tangent at the first or the last point.
+// It returns false if x is inside the range and ErrOutOfRange if the curve is not extended to x.
+func extendCurve(x float64, xs, ys []float64, leftSlope, rightSlope float64, extendLeft, extendRight bool) (float64, bool, error) {
+	n := len(xs)
+
+	switch {
+	case x < xs[0]:
+		if !extendLeft {
+			return 0, true, ErrOutOfRange
+		}
+
+		return ys[0] + leftSlope*(x-xs[0]), true, nil
+	case x >= xs[n-1]:
+		if !extendRight {
+			return 0, true, ErrOutOfRange
+		}
+
+		return ys[n-1] + rightSlope*(x-xs[n-1]), true, nil
+	}
+
+	return 0, false, nil
+}
+
+// curvePrice converts the y coordinate of a curve to a price
+func curvePrice(y float64, log bool) float64 {
+	if !log {
+		return y
+	}
+
+	return math.Pow(10, y)
+}
+
+// secants returns the slopes of the lines between consecutive points
+func secants(xs, ys []float64) []float64 {
+	d := make([]float64, len(xs)-1)
+	for i := range d {
+		d[i] = (ys[i+1] - ys[i]) / (xs[i+1] - xs[i])
+	}
+
+	return d
+}
+
+// monotoneSlopes returns Fritsch-Butland tangents, they are zero at local extremes which keeps the curve monotone between the points
+func monotoneSlopes(xs, ys []float64) []float64 {
+	n := len(xs)
+	d := secants(xs, ys)
+	m := make([]float64, n)
+
+	m[0], m[n-1] = d[0], d[n-2]
+
+	for i := 1; i < n-1; i++ {
+		if d[i-1]*d[i] <= 0 {
+			continue
+		}
+
+		h0, h1 := xs[i]-xs[i-1], xs[i+1]-xs[i]
+		w0, w1 := 2*h1+h0, h1+2*h0
+		m[i] = (w0 + w1) / (w0/d[i-1] + w1/d[i])
+	}
+
+	return m
+}
+
+// catmullRomSlopes returns tangents of a Catmull-Rom spline with non-uniform spacing, the end tangents are the secants of the end segments
+func catmullRomSlopes(xs, ys []float64) []float64 {
+	n := len(xs)
+	d := secants(xs, ys)
+	m := make([]float64, n)
+
+	m[0], m[n-1] = d[0], d[n-2]
+
+	for i := 1; i < n-1; i++ {
+		m[i] = (ys[i+1] - ys[i-1]) / (xs[i+1] - xs[i-1])
+	}
+
+	return m
+}
@@ -0,0 +1,144 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSpline(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []geometry.Point
+		kind      geometry.SplineKind
+		expectErr bool
+	}{
+		{name: "no points", kind: geometry.SplineMonotone, expectErr: true},
+		{name: "1 point", points: []geometry.Point{{time.Unix(0, 0), 1}}, kind: geometry.SplineMonotone, expectErr: true},
+		{name: "2 points", points: []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}}, kind: geometry.SplineMonotone},
+		{name: "same date", points: []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(0, 0), 2}}, kind: geometry.SplineCatmullRom, expectErr: true},
+		{name: "unknown kind", points: []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}}, kind: "cubic", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewSpline(tt.points, tt.kind, false, false)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, s == nil)
+
+			ls, err := geometry.NewLogSpline(tt.points, tt.kind, false, false)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, ls == nil)
+		})
+	}
+
+	_, err := geometry.NewLogSpline([]geometry.Point{{time.Unix(0, 0), 0}, {time.Unix(1, 0), 2}}, geometry.SplineMonotone, false, false)
+	assert.Error(t, err)
+}
+
+func TestSpline_ThroughPoints(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(20, 0), 20},
+		{time.Unix(0, 0), 10},
+		{time.Unix(10, 0), 30},
+		{time.Unix(30, 0), 40},
+	}
+
+	constructors := map[string]func([]geometry.Point, geometry.SplineKind, bool, bool) (*geometry.Spline, error){
+		"linear": geometry.NewSpline,
+		"log":    geometry.NewLogSpline,
+	}
+
+	for name, newSpline := range constructors {
+		for _, kind := range []geometry.SplineKind{geometry.SplineMonotone, geometry.SplineCatmullRom} {
+			t.Run(name+" "+string(kind), func(t *testing.T) {
+				s, err := newSpline(points, kind, false, true)
+				assert.NoError(t, err)
+
+				for _, p := range points {
+					y, err := s.At(p.Date)
+					assert.NoError(t, err)
+					assert.InDelta(t, p.Price, y, 1e-9)
+				}
+			})
+		}
+	}
+}
+
+func TestSpline_At(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(0, 0), 10},
+		{time.Unix(10, 0), 30},
+		{time.Unix(20, 0), 20},
+		{time.Unix(30, 0), 40},
+	}
+
+	tests := []struct {
+		name                    string
+		kind                    geometry.SplineKind
+		extendLeft, extendRight bool
+		x                       time.Time
+		y                       float64
+		err                     error
+	}{
+		{name: "monotone", kind: geometry.SplineMonotone, x: time.Unix(12, 0), y: 28.96},
+		{name: "catmull-rom", kind: geometry.SplineCatmullRom, x: time.Unix(12, 0), y: 29.44},
+		{name: "left - not extended", kind: geometry.SplineMonotone, x: time.Unix(-10, 0), err: geometry.ErrOutOfRange},
+		{name: "right - not extended", kind: geometry.SplineMonotone, x: time.Unix(30, 0), err: geometry.ErrOutOfRange},
+		{name: "left - extended", kind: geometry.SplineMonotone, extendLeft: true, x: time.Unix(-10, 0), y: -10},
+		{name: "right - extended", kind: geometry.SplineCatmullRom, extendRight: true, x: time.Unix(40, 0), y: 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewSpline(points, tt.kind, tt.extendLeft, tt.extendRight)
+			assert.NoError(t, err)
+
+			y, err := s.At(tt.x)
+			assert.ErrorIs(t, err, tt.err)
+			assert.InDelta(t, tt.y, y, 1e-9)
+		})
+	}
+}
+
+func TestSpline_AtZeroValue(t *testing.T) {
+	_, err := (&geometry.Spline{}).At(time.Now())
+	assert.Error(t, err)
+
+	// points set without the constructor are not prepared
+	_, err = (&geometry.Spline{Points: []geometry.Point{{Date: time.Unix(0, 0), Price: 1}, {Date: time.Unix(60, 0), Price: 2}}}).At(time.Unix(30, 0))
+	assert.Error(t, err)
+}
+
+func TestSpline_MonotoneDoesNotOvershoot(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(0, 0), 10},
+		{time.Unix(10, 0), 30},
+		{time.Unix(20, 0), 20},
+		{time.Unix(30, 0), 40},
+	}
+
+	s, err := geometry.NewSpline(points, geometry.SplineMonotone, false, false)
+	assert.NoError(t, err)
+
+	for i := 0; i < len(points)-1; i++ {
+		p0, p1 := points[i], points[i+1]
+		prev := p0.Price
+
+		for sec := p0.Date.Unix(); sec <= p1.Date.Unix() && sec < 30; sec++ {
+			y, err := s.At(time.Unix(sec, 0))
+			assert.NoError(t, err)
+
+			// every segment moves in one direction only
+			if p1.Price > p0.Price {
+				assert.GreaterOrEqual(t, y, prev)
+			} else {
+				assert.LessOrEqual(t, y, prev)
+			}
+
+			prev = y
+		}
+	}
+}
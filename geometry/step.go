@@ -0,0 +1,54 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Step is a step function, the price of every point holds until the date of the next point.
+// It's out of range before the first point and from RightLimit on, the price of the last point holds indefinitely when RightLimit is zero.
+type Step struct {
+	Points     []Point
+	RightLimit time.Time
+}
+
+// NewStep is a Step constructor, it accepts at least 1 point, the points are sorted by time and their dates have to be unique.
+// Zero rightLimit extends the last step indefinitely to the right.
+func NewStep(points []Point, rightLimit time.Time) (*Step, error) {
+	if len(points) == 0 {
+		return nil, errors.New("error creating step: at least 1 point is required")
+	}
+
+	points = sortPoints(append([]Point{}, points...)...)
+
+	for i := 1; i < len(points); i++ {
+		if points[i].Date.Equal(points[i-1].Date) {
+			return nil, fmt.Errorf("error creating step: points %d and %d have the same date", i-1, i)
+		}
+	}
+
+	if !rightLimit.IsZero() && !points[len(points)-1].Date.Before(rightLimit) {
+		return nil, errors.New("error creating step: right limit is not after the last point")
+	}
+
+	return &Step{Points: points, RightLimit: rightLimit}, nil
+}
+
+func (s *Step) At(t time.Time) (float64, error) {
+	if len(s.Points) == 0 || !lineInRange(t, s.Points[0].Date, s.RightLimit) {
+		return 0, ErrOutOfRange
+	}
+
+	price := s.Points[0].Price
+
+	for _, p := range s.Points[1:] {
+		if t.Before(p.Date) {
+			break
+		}
+
+		price = p.Price
+	}
+
+	return price, nil
+}
@@ -0,0 +1,67 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStep(t *testing.T) {
+	tests := []struct {
+		name       string
+		points     []geometry.Point
+		rightLimit time.Time
+		expectErr  bool
+	}{
+		{name: "no points", expectErr: true},
+		{name: "1 point", points: []geometry.Point{{time.Unix(0, 0), 1}}},
+		{name: "same date", points: []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(0, 0), 2}}, expectErr: true},
+		{name: "right limit", points: []geometry.Point{{time.Unix(0, 0), 1}}, rightLimit: time.Unix(10, 0)},
+		{name: "right limit - at last point", points: []geometry.Point{{time.Unix(0, 0), 1}}, rightLimit: time.Unix(0, 0), expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewStep(tt.points, tt.rightLimit)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, s == nil)
+		})
+	}
+}
+
+func TestStep_At(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(20, 0), 30},
+		{time.Unix(0, 0), 10},
+		{time.Unix(10, 0), 20},
+	}
+
+	tests := []struct {
+		name       string
+		rightLimit time.Time
+		x          time.Time
+		y          float64
+		err        error
+	}{
+		{name: "before first point", x: time.Unix(-1, 0), err: geometry.ErrOutOfRange},
+		{name: "first point", x: time.Unix(0, 0), y: 10},
+		{name: "first step", x: time.Unix(9, 0), y: 10},
+		{name: "second point", x: time.Unix(10, 0), y: 20},
+		{name: "last step - not limited", x: time.Unix(1000, 0), y: 30},
+		{name: "last step - limited", rightLimit: time.Unix(30, 0), x: time.Unix(29, 0), y: 30},
+		{name: "right limit", rightLimit: time.Unix(30, 0), x: time.Unix(30, 0), err: geometry.ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewStep(points, tt.rightLimit)
+			assert.NoError(t, err)
+
+			y, err := s.At(tt.x)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.y, y)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package plotor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+)
+
+// PriceClient is implemented by clients that can report the market price of a symbol, it's required by alerts
+type PriceClient interface {
+	MarketPrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// AlertSide is the side of the plot the market price is on
+type AlertSide string
+
+const (
+	AlertSideAbove AlertSide = "ABOVE"
+	AlertSideBelow AlertSide = "BELOW"
+)
+
+// Alert turns a plot order into an alert line, instead of placing an order the market price
+// is compared to the plot every interval and an event is published when it crosses the plot
+type Alert struct {
+	Symbol string
+	// Side is the side of the plot the market price was on at the last tick, a price equal to the plot keeps the side
+	Side AlertSide
+	// MarketPrice is the market price at the last tick
+	MarketPrice float64
+	// Crossings is the number of times the market price crossed the plot
+	Crossings int
+}
+
+// cross moves the alert to the side of the plot the market price is on, it returns true if the market price crossed the plot
+func (a *Alert) cross(marketPrice, plotPrice float64) bool {
+	a.MarketPrice = marketPrice
+
+	side, crossed := crossSide(a.Side, marketPrice, plotPrice)
+	if crossed {
+		a.Crossings++
+	}
+
+	a.Side = side
+
+	return crossed
+}
+
+// crossSide returns the side of the plot the market price is on and true if it's different from the previous known side,
+// a market price equal to the plot price keeps the previous side
+func crossSide(prev AlertSide, marketPrice, plotPrice float64) (AlertSide, bool) {
+	side := prev
+	switch {
+	case marketPrice > plotPrice:
+		side = AlertSideAbove
+	case marketPrice < plotPrice:
+		side = AlertSideBelow
+	}
+
+	return side, prev != "" && side != prev
+}
+
+// crossEvent returns the event published when the market price crosses the plot to given side
+func crossEvent(side AlertSide) EventType {
+	if side == AlertSideAbove {
+		return EventCrossedAbove
+	}
+
+	return EventCrossedBelow
+}
+
+// CreateAlert creates an alert plot order that has no order, the client has to implement PriceClient.
+// The alert runs until it's stopped or the plot goes out of range, only LEAVE and WAIT out of range policies are supported
+// and it can not have a trigger.
+func (p *PlotOrderer) CreateAlert(ctx context.Context, symbol string, plot geometry.Plot, interval time.Duration, opts ...Option) (*PlotOrder, error) {
+	pc, ok := p.client.(PriceClient)
+	if !ok {
+		return nil, errors.New("client does not support market prices")
+	}
+
+	if symbol == "" {
+		return nil, errors.New("symbol can not be empty")
+	}
+
+	plotPrice, err := plot.At(p.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	po := NewPlotOrder(nil, plot, interval, opts...)
+
+	if po.OutOfRange != OutOfRangeLeave && po.OutOfRange != OutOfRangeWait {
+		return nil, fmt.Errorf("out of range policy %s is not supported by alerts", po.OutOfRange)
+	}
+
+	// a trigger places an order once it's crossed and alerts have none
+	if po.Trigger != nil {
+		return nil, errors.New("alerts do not support triggers")
+	}
+
+	marketPrice, err := pc.MarketPrice(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("error getting market price: %w", err)
+	}
+
+	po.Alert = &Alert{Symbol: symbol}
+	po.Alert.cross(marketPrice, plotPrice)
+
+	p.register(ctx, po)
+	po.publish(EventCreated)
+	p.run(ctx, po)
+
+	return po, nil
+}
+
+// alertHandler compares the market price to the price of the plot and publishes an event when the market price crosses the plot
+func (p *PlotOrderer) alertHandler(ctx context.Context, po *PlotOrder) Handler {
+	return func(_ ClientOrder, price float64) error {
+		return po.retry(func() error {
+			pc, ok := p.client.(PriceClient)
+			if !ok {
+				return errors.New("client does not support market prices")
+			}
+
+			marketPrice, err := pc.MarketPrice(ctx, po.symbol())
+			if err != nil {
+				return err
+			}
+
+			po.mu.Lock()
+			crossed := po.Alert.cross(marketPrice, price)
+			side := po.Alert.Side
+			po.mu.Unlock()
+
+			if crossed {
+				po.publish(crossEvent(side))
+			}
+
+			return nil
+		})
+	}
+}
+
+func (a *Alert) copy() *Alert {
+	if a == nil {
+		return nil
+	}
+
+	cp := *a
+	return &cp
+}
+
+func (po *PlotOrder) alert() *Alert {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return po.Alert.copy()
+}
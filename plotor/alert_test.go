@@ -0,0 +1,99 @@
+package plotor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlotOrderer_CreateAlert(t *testing.T) {
+	clock := plotor.NewManualClock(time.Date(2023, 1, 15, 0, 0, 30, 0, time.UTC))
+	scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+	defer scheduler.Stop()
+
+	client := newFakeClient()
+	client.setMarketPrice("BTCUSDT", 90)
+	orderer := plotor.NewPlotOrderer(client)
+	orderer.SetScheduler(scheduler)
+
+	events, unsubscribe := orderer.Subscribe(0)
+	defer unsubscribe()
+
+	po, err := orderer.CreateAlert(context.Background(), "BTCUSDT", &geometry.Line{B: 100}, time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, po.Snapshot().Order)
+	assert.Equal(t, &plotor.Alert{Symbol: "BTCUSDT", Side: plotor.AlertSideBelow, MarketPrice: 90}, po.Snapshot().Alert)
+
+	e := <-events
+	assert.Equal(t, plotor.EventCreated, e.Type)
+	assert.Equal(t, "BTCUSDT", e.Symbol)
+
+	tick := func(marketPrice float64) {
+		client.setMarketPrice("BTCUSDT", marketPrice)
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+	}
+
+	// touching the plot keeps the side
+	tick(100)
+	tick(110)
+
+	crossed := <-events
+	assert.Equal(t, plotor.EventCrossedAbove, crossed.Type)
+	assert.Equal(t, 110.0, crossed.MarketPrice)
+
+	tick(120)
+	tick(95)
+	assert.Equal(t, plotor.EventCrossedBelow, (<-events).Type)
+
+	clock.BlockUntil(1)
+
+	snapshot := po.Snapshot()
+	assert.Equal(t, plotor.StatusRunning, snapshot.Status)
+	assert.Equal(t, &plotor.Alert{Symbol: "BTCUSDT", Side: plotor.AlertSideBelow, MarketPrice: 95, Crossings: 2}, snapshot.Alert)
+	assert.Empty(t, client.prices)
+
+	history, err := orderer.History(po.ID, plotor.HistoryFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, history, 4)
+	assert.Equal(t, 95.0, history[3].MarketPrice)
+	assert.Equal(t, 100.0, history[3].PlotPrice)
+
+	assert.Len(t, orderer.List(plotor.ListFilter{Symbol: "BTCUSDT"}), 1)
+
+	got, err := orderer.Get(context.Background(), po.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.Alert, got.Alert)
+
+	assert.NoError(t, orderer.Stop(context.Background(), po.ID, true))
+	assert.Equal(t, plotor.EventStopped, (<-events).Type)
+}
+
+func TestPlotOrderer_CreateAlertInvalid(t *testing.T) {
+	client := newFakeClient()
+	orderer := plotor.NewPlotOrderer(client)
+
+	_, err := orderer.CreateAlert(context.Background(), "BTCUSDT", &geometry.Line{B: 100}, time.Minute)
+	assert.Error(t, err, "no market price")
+
+	client.setMarketPrice("BTCUSDT", 90)
+
+	_, err = orderer.CreateAlert(context.Background(), "", &geometry.Line{B: 100}, time.Minute)
+	assert.Error(t, err)
+
+	_, err = orderer.CreateAlert(context.Background(), "BTCUSDT", &geometry.Line{B: 100}, time.Minute, plotor.WithOutOfRange(plotor.OutOfRangeCancel))
+	assert.Error(t, err)
+
+	_, err = orderer.CreateAlert(context.Background(), "BTCUSDT", neverValid{}, time.Minute)
+	assert.Error(t, err)
+
+	// an alert has no order to place when the trigger is crossed
+	_, err = orderer.CreateAlert(context.Background(), "BTCUSDT", &geometry.Line{B: 100}, time.Minute,
+		plotor.WithTrigger("BTCUSDT", &geometry.Line{B: 95}, plotor.AlertSideAbove))
+	assert.Error(t, err)
+	assert.Empty(t, orderer.List(plotor.ListFilter{}))
+}
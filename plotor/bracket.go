@@ -0,0 +1,492 @@
+package plotor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/google/uuid"
+)
+
+// BracketStatus is the stage of a bracket
+type BracketStatus string
+
+const (
+	// BracketStatusEntry - the entry plot order is waiting to be filled
+	BracketStatusEntry BracketStatus = "ENTRY"
+	// BracketStatusOpen - the entry was filled, the take-profit and stop-loss plot orders follow their plots
+	BracketStatusOpen BracketStatus = "OPEN"
+	// BracketStatusTakeProfit - the take-profit was filled and the stop-loss was cancelled
+	BracketStatusTakeProfit BracketStatus = "TAKE_PROFIT"
+	// BracketStatusStopLoss - the stop-loss was filled and the take-profit was cancelled
+	BracketStatusStopLoss BracketStatus = "STOP_LOSS"
+	// BracketStatusClosed - the bracket was stopped, or its entry or both exits were stopped before they were filled
+	BracketStatusClosed BracketStatus = "CLOSED"
+	// BracketStatusFailed - the entry failed, was closed after it was partially filled or the exits could not be placed
+	BracketStatusFailed BracketStatus = "FAILED"
+)
+
+// Terminal returns true if the bracket will not place or cancel any more orders
+func (s BracketStatus) Terminal() bool {
+	return s != BracketStatusEntry && s != BracketStatusOpen
+}
+
+// ErrBothExitsFilled is set as the error of a bracket when its exit was filled before the other exit could be cancelled,
+// the position was then closed twice and the bracket keeps the status of the exit that was filled first
+var ErrBothExitsFilled = errors.New("both exits were filled")
+
+// ErrEntryPartiallyFilled is set as the error of a failed bracket when its entry was stopped, cancelled or expired
+// after it was partially filled, the exits are not placed so the filled quantity is left without them
+var ErrEntryPartiallyFilled = errors.New("entry was closed after it was partially filled, the filled quantity has no exits")
+
+// BracketLeg describes one of the plot orders of a bracket
+type BracketLeg struct {
+	// OrderData is passed to Client.CreateOrder, the stop-loss is usually a stop order e.g. STOP_MARKET,
+	// the price of its plot is used as the stop price.
+	// The exits are created with their OrderData as it is, they are not sized to the quantity the entry was filled with,
+	// they are placed once the entry order is FILLED so they should have the quantity of the entry.
+	OrderData any
+	Plot      geometry.Plot
+	// Options are applied to the plot order of the leg
+	Options []Option
+}
+
+func (l BracketLeg) Validate() error {
+	if l.Plot == nil {
+		return errors.New("plot can not be empty")
+	}
+
+	return nil
+}
+
+// options returns the options of the leg making the plot order use given ID
+func (l BracketLeg) options(plotOrderID string) []Option {
+	opts := append([]Option{}, l.Options...)
+	return append(opts, func(po *PlotOrder) { po.ID = plotOrderID })
+}
+
+// Bracket is an entry plot order followed by a take-profit and a stop-loss plot order that are placed once the entry is filled.
+// The exits are one-cancels-the-other, when either of them is filled the other one is stopped and its order is cancelled.
+// Brackets can be persisted using OnBracketUpdate and linked to their restored plot orders again with RestoreBracket.
+type Bracket struct {
+	ID     string
+	Status BracketStatus
+	Err    error
+	// EntryID, TakeProfitID and StopLossID are the IDs of the plot orders of the bracket,
+	// the take-profit and stop-loss plot orders exist once the entry is filled
+	EntryID      string
+	TakeProfitID string
+	StopLossID   string
+	Interval     time.Duration
+	// EntryPartiallyFilled is set when the order of the entry becomes partially filled
+	EntryPartiallyFilled bool
+
+	takeProfit BracketLeg
+	stopLoss   BracketLeg
+	// ctx is used to place and cancel the exits
+	ctx context.Context
+	mu  *sync.Mutex
+}
+
+func (b *Bracket) snapshot() *Bracket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.copy()
+}
+
+// copy returns a copy of the bracket, the bracket has to be locked
+func (b *Bracket) copy() *Bracket {
+	cp := *b
+	cp.mu = &sync.Mutex{}
+
+	return &cp
+}
+
+// OnBracketUpdate sets a function that is called with a copy of a bracket every time its status changes or its exits are placed,
+// it can be used to persist brackets. It's called while the bracket is locked so it must not call the bracket methods of the orderer.
+func (p *PlotOrderer) OnBracketUpdate(f func(b *Bracket)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onBracketUpdate = f
+}
+
+// bracketUpdated calls the OnBracketUpdate function, the bracket has to be locked
+func (p *PlotOrderer) bracketUpdated(b *Bracket) {
+	p.mu.Lock()
+	f := p.onBracketUpdate
+	p.mu.Unlock()
+
+	if f != nil {
+		f(b.copy())
+	}
+}
+
+// CreateBracket creates the entry plot order of a bracket, the take-profit and stop-loss plot orders
+// are created by the orderer when the entry is filled, all of them are updated every interval
+func (p *PlotOrderer) CreateBracket(ctx context.Context, entry, takeProfit, stopLoss BracketLeg, interval time.Duration) (*Bracket, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid entry: %w", err)
+	}
+
+	if err := takeProfit.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid take-profit: %w", err)
+	}
+
+	if err := stopLoss.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid stop-loss: %w", err)
+	}
+
+	b := &Bracket{
+		ID:           uuid.NewString(),
+		Status:       BracketStatusEntry,
+		EntryID:      uuid.NewString(),
+		TakeProfitID: uuid.NewString(),
+		StopLossID:   uuid.NewString(),
+		Interval:     interval,
+		takeProfit:   takeProfit,
+		stopLoss:     stopLoss,
+		ctx:          ctx,
+		mu:           &sync.Mutex{},
+	}
+
+	// the bracket has to be known before the entry is created, the entry could be filled right away
+	p.mu.Lock()
+	p.brackets[b.ID] = b
+	p.mu.Unlock()
+
+	if _, err := p.Create(ctx, entry.OrderData, entry.Plot, interval, entry.options(b.EntryID)...); err != nil {
+		p.mu.Lock()
+		delete(p.brackets, b.ID)
+		p.mu.Unlock()
+
+		return nil, fmt.Errorf("error creating entry: %w", err)
+	}
+
+	return b.snapshot(), nil
+}
+
+// RestoreBracket links the plot orders of a bracket that was created before, e.g. by a previous process.
+// The plot orders of the entry and of the exits that were placed have to be restored (see Restore) before the bracket,
+// events they published in the meantime are caught up with, e.g. the exits are placed when the entry is filled already.
+// A leg that should be running but was not restored fails the bracket, its sibling is left in place.
+func (p *PlotOrderer) RestoreBracket(ctx context.Context, b *Bracket, takeProfit, stopLoss BracketLeg) (*Bracket, error) {
+	if b.Status.Terminal() {
+		return nil, fmt.Errorf("bracket with status %s can not be restored", b.Status)
+	}
+
+	if err := takeProfit.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid take-profit: %w", err)
+	}
+
+	if err := stopLoss.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid stop-loss: %w", err)
+	}
+
+	restored := b.copy()
+	restored.takeProfit = takeProfit
+	restored.stopLoss = stopLoss
+	restored.ctx = ctx
+
+	p.mu.Lock()
+	if _, ok := p.brackets[restored.ID]; ok {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("bracket %s already exists", restored.ID)
+	}
+	p.brackets[restored.ID] = restored
+	p.mu.Unlock()
+
+	restored.mu.Lock()
+	defer restored.mu.Unlock()
+
+	legs := []string{restored.EntryID}
+	if restored.Status == BracketStatusOpen {
+		legs = []string{restored.TakeProfitID, restored.StopLossID}
+	}
+
+	for _, id := range legs {
+		if restored.Status.Terminal() {
+			break
+		}
+
+		po, err := p.plotOrder(id)
+		if err != nil {
+			restored.Status = BracketStatusFailed
+			restored.Err = fmt.Errorf("plot order %s of the bracket was not restored", id)
+			break
+		}
+
+		snapshot := po.Snapshot()
+		if id == restored.EntryID && snapshot.Order != nil && snapshot.Order.OrderStatus() == OrderStatusPartiallyFilled {
+			restored.EntryPartiallyFilled = true
+		}
+
+		// the plot order could have been closed before the bracket was linked to it
+		if e, ok := statusEvent(snapshot.Status); ok {
+			p.advance(restored, po.event(e))
+		}
+	}
+
+	p.bracketUpdated(restored)
+
+	return restored.copy(), nil
+}
+
+// Bracket returns a copy of the bracket
+func (p *PlotOrderer) Bracket(bracketID string) (*Bracket, error) {
+	p.mu.Lock()
+	b, ok := p.brackets[bracketID]
+	p.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("bracket %s not found", bracketID)
+	}
+
+	return b.snapshot(), nil
+}
+
+// Brackets returns copies of all brackets sorted by ID
+func (p *PlotOrderer) Brackets() []*Bracket {
+	p.mu.Lock()
+	brackets := make([]*Bracket, 0, len(p.brackets))
+	for _, b := range p.brackets {
+		brackets = append(brackets, b)
+	}
+	p.mu.Unlock()
+
+	list := make([]*Bracket, 0, len(brackets))
+	for _, b := range brackets {
+		list = append(list, b.snapshot())
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	return list
+}
+
+// StopBracket stops the active plot orders of the bracket, if cancelOrder is true then it also cancels their orders on the exchange.
+// A bracket whose entry was partially filled is failed with ErrEntryPartiallyFilled instead of being closed.
+func (p *PlotOrderer) StopBracket(ctx context.Context, bracketID string, cancelOrder bool) error {
+	p.mu.Lock()
+	b, ok := p.brackets[bracketID]
+	p.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("bracket %s not found", bracketID)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Status.Terminal() {
+		return fmt.Errorf("bracket %s is already closed: %s", bracketID, b.Status)
+	}
+
+	if b.Status == BracketStatusEntry && b.EntryPartiallyFilled {
+		b.Status = BracketStatusFailed
+		b.Err = ErrEntryPartiallyFilled
+	} else {
+		b.Status = BracketStatusClosed
+	}
+
+	p.bracketUpdated(b)
+
+	for _, id := range []string{b.EntryID, b.TakeProfitID, b.StopLossID} {
+		if !p.active(id) {
+			continue
+		}
+
+		if err := p.Stop(ctx, id, cancelOrder); err != nil {
+			return fmt.Errorf("error stopping plot order %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// publish delivers the event to subscribers, events closing a plot order are also passed to its bracket
+func (p *PlotOrderer) publish(e Event) {
+	p.events.publish(e)
+
+	switch e.Type {
+	case EventFilled, EventStopped, EventError, EventPartiallyFilled:
+		// the event can be published while the orderer is locked e.g. by Stop
+		go p.bracketEvent(e)
+	}
+}
+
+// bracketEvent moves the bracket of the plot order forward when the plot order is closed or its entry is partially filled
+func (p *PlotOrderer) bracketEvent(e Event) {
+	b := p.bracketOf(e.PlotOrderID)
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if p.advance(b, e) {
+		p.bracketUpdated(b)
+	}
+}
+
+// advance applies the event of a closed plot order to the bracket, it returns false if the event was ignored.
+// The bracket has to be locked.
+func (p *PlotOrderer) advance(b *Bracket, e Event) bool {
+	if e.Type == EventPartiallyFilled {
+		return p.entryPartiallyFilled(b, e)
+	}
+
+	if b.Status.Terminal() {
+		// the sibling of the filled exit could have been filled before it was stopped
+		if e.Type == EventFilled && (b.Status == BracketStatusTakeProfit && e.PlotOrderID == b.StopLossID ||
+			b.Status == BracketStatusStopLoss && e.PlotOrderID == b.TakeProfitID) && !errors.Is(b.Err, ErrBothExitsFilled) {
+			b.bothExitsFilled()
+			return true
+		}
+
+		return false
+	}
+
+	switch e.PlotOrderID {
+	case b.EntryID:
+		// the entry could be seen closed twice when the bracket is restored
+		if b.Status != BracketStatusEntry {
+			return false
+		}
+
+		switch {
+		case e.Type == EventFilled:
+			p.placeExits(b)
+		case e.Type == EventError:
+			b.Status = BracketStatusFailed
+			b.Err = fmt.Errorf("entry failed: %s", e.Error)
+			if b.EntryPartiallyFilled {
+				b.Err = errors.Join(b.Err, ErrEntryPartiallyFilled)
+			}
+		case b.EntryPartiallyFilled:
+			b.Status = BracketStatusFailed
+			b.Err = ErrEntryPartiallyFilled
+		default:
+			b.Status = BracketStatusClosed
+		}
+	case b.TakeProfitID:
+		p.exitClosed(b, e, BracketStatusTakeProfit, b.StopLossID)
+	case b.StopLossID:
+		p.exitClosed(b, e, BracketStatusStopLoss, b.TakeProfitID)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// entryPartiallyFilled marks the entry of the bracket as partially filled. The event is delivered concurrently with the one
+// closing the entry so a bracket that was closed before its exits were placed is failed here.
+func (p *PlotOrderer) entryPartiallyFilled(b *Bracket, e Event) bool {
+	if e.PlotOrderID != b.EntryID || b.EntryPartiallyFilled {
+		return false
+	}
+
+	b.EntryPartiallyFilled = true
+
+	if b.Status == BracketStatusClosed && !p.exists(b.TakeProfitID) {
+		b.Status = BracketStatusFailed
+		b.Err = ErrEntryPartiallyFilled
+	}
+
+	return true
+}
+
+// placeExits creates the take-profit and stop-loss plot orders, the take-profit is stopped if the stop-loss can not be created
+func (p *PlotOrderer) placeExits(b *Bracket) {
+	tp, sl := b.takeProfit, b.stopLoss
+
+	if _, err := p.Create(b.ctx, tp.OrderData, tp.Plot, b.Interval, tp.options(b.TakeProfitID)...); err != nil {
+		b.Status = BracketStatusFailed
+		b.Err = fmt.Errorf("error creating take-profit: %w", err)
+		return
+	}
+
+	if _, err := p.Create(b.ctx, sl.OrderData, sl.Plot, b.Interval, sl.options(b.StopLossID)...); err != nil {
+		b.Status = BracketStatusFailed
+		b.Err = fmt.Errorf("error creating stop-loss: %w", err)
+
+		if err := p.Stop(b.ctx, b.TakeProfitID, true); err != nil {
+			b.Err = errors.Join(b.Err, fmt.Errorf("error cancelling take-profit: %w", err))
+		}
+
+		return
+	}
+
+	b.Status = BracketStatusOpen
+}
+
+// exitClosed cancels the sibling of a filled exit, an exit that was stopped or failed leaves its sibling in place
+// and the bracket is closed once both of them are closed. ErrBothExitsFilled is recorded if the sibling was filled too.
+func (p *PlotOrderer) exitClosed(b *Bracket, e Event, filled BracketStatus, siblingID string) {
+	if e.Type != EventFilled {
+		if !p.active(siblingID) {
+			b.Status = BracketStatusClosed
+		}
+
+		return
+	}
+
+	b.Status = filled
+
+	// a sibling that was filled already publishes its own event
+	if !p.active(siblingID) {
+		return
+	}
+
+	if err := p.Stop(b.ctx, siblingID, true); err != nil {
+		b.Err = fmt.Errorf("error cancelling plot order %s: %w", siblingID, err)
+	}
+
+	// the order of the sibling could have been filled on the exchange right before it was cancelled
+	if sibling, err := p.Get(b.ctx, siblingID); err == nil && sibling.Order.OrderStatus() == OrderStatusFilled {
+		b.bothExitsFilled()
+	}
+}
+
+func (b *Bracket) bothExitsFilled() {
+	if !errors.Is(b.Err, ErrBothExitsFilled) {
+		b.Err = errors.Join(b.Err, ErrBothExitsFilled)
+	}
+}
+
+// bracketOf returns the bracket the plot order belongs to, nil if there is none
+func (p *PlotOrderer) bracketOf(plotOrderID string) *Bracket {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, b := range p.brackets {
+		// the IDs are never changed so they can be read without locking the bracket
+		if plotOrderID == b.EntryID || plotOrderID == b.TakeProfitID || plotOrderID == b.StopLossID {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// exists returns true if the orderer has the plot order
+func (p *PlotOrderer) exists(plotOrderID string) bool {
+	_, err := p.plotOrder(plotOrderID)
+	return err == nil
+}
+
+// active returns true if the plot order exists and has not reached a terminal status
+func (p *PlotOrderer) active(plotOrderID string) bool {
+	po, err := p.plotOrder(plotOrderID)
+	if err != nil {
+		return false
+	}
+
+	return !po.Snapshot().Status.Terminal()
+}
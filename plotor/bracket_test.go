@@ -0,0 +1,305 @@
+package plotor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBracketOrderer(t *testing.T, client *fakeClient) (*plotor.PlotOrderer, *plotor.ManualClock) {
+	t.Helper()
+
+	clock := plotor.NewManualClock(time.Date(2023, 1, 15, 0, 0, 30, 0, time.UTC))
+	scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+	t.Cleanup(scheduler.Stop)
+
+	orderer := plotor.NewPlotOrderer(client)
+	orderer.SetScheduler(scheduler)
+
+	return orderer, clock
+}
+
+func waitBracketStatus(t *testing.T, orderer *plotor.PlotOrderer, bracketID string, status plotor.BracketStatus) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		b, err := orderer.Bracket(bracketID)
+		return err == nil && b.Status == status
+	}, time.Second, time.Millisecond)
+}
+
+func TestPlotOrderer_Bracket(t *testing.T) {
+	tests := []struct {
+		name string
+		// fillTakeProfit decides which exit is filled
+		fillTakeProfit bool
+		status         plotor.BracketStatus
+	}{
+		{name: "take-profit", fillTakeProfit: true, status: plotor.BracketStatusTakeProfit},
+		{name: "stop-loss", fillTakeProfit: false, status: plotor.BracketStatusStopLoss},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := newFakeClient()
+			orderer, clock := newBracketOrderer(t, client)
+
+			b, err := orderer.CreateBracket(ctx,
+				plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1}},
+				plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: 100}},
+				plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: -100}},
+				time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, plotor.BracketStatusEntry, b.Status)
+
+			// the exits are not placed before the entry is filled
+			_, err = orderer.Get(ctx, b.TakeProfitID)
+			assert.Error(t, err)
+
+			client.setStatus(plotor.OrderStatusFilled)
+			clock.BlockUntil(1)
+			clock.Advance(time.Minute)
+
+			waitBracketStatus(t, orderer, b.ID, plotor.BracketStatusOpen)
+
+			entry, err := orderer.Get(ctx, b.EntryID)
+			assert.NoError(t, err)
+			assert.Equal(t, plotor.StatusFilled, entry.Status)
+
+			tp, err := orderer.Get(ctx, b.TakeProfitID)
+			assert.NoError(t, err)
+			sl, err := orderer.Get(ctx, b.StopLossID)
+			assert.NoError(t, err)
+
+			now := float64(clock.Now().Unix())
+			assert.Equal(t, now+100, tp.Order.OrderPrice())
+			assert.Equal(t, now-100, sl.Order.OrderPrice())
+
+			filled, sibling := tp, sl
+			if !tt.fillTakeProfit {
+				filled, sibling = sl, tp
+			}
+
+			client.setOrderStatus(filled.Order.(*fakeOrder).ID, plotor.OrderStatusFilled)
+			clock.BlockUntil(1)
+			clock.Advance(time.Minute)
+
+			waitBracketStatus(t, orderer, b.ID, tt.status)
+
+			assert.Eventually(t, func() bool {
+				po, err := orderer.Get(ctx, sibling.ID)
+				return err == nil && po.Status == plotor.StatusStopped && po.Order.OrderStatus() == plotor.OrderStatusCanceled
+			}, time.Second, time.Millisecond)
+
+			po, err := orderer.Get(ctx, filled.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, plotor.StatusFilled, po.Status)
+		})
+	}
+}
+
+func TestPlotOrderer_BracketBothExitsFilled(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	orderer, clock := newBracketOrderer(t, client)
+
+	b, err := orderer.CreateBracket(ctx,
+		plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1}},
+		plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: 100}},
+		plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: -100}},
+		time.Minute)
+	assert.NoError(t, err)
+
+	client.setStatus(plotor.OrderStatusFilled)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	waitBracketStatus(t, orderer, b.ID, plotor.BracketStatusOpen)
+
+	tp, err := orderer.Get(ctx, b.TakeProfitID)
+	assert.NoError(t, err)
+	sl, err := orderer.Get(ctx, b.StopLossID)
+	assert.NoError(t, err)
+
+	// both exits are filled on the exchange before the bracket sees either of them
+	client.setOrderStatus(tp.Order.(*fakeOrder).ID, plotor.OrderStatusFilled)
+	client.setOrderStatus(sl.Order.(*fakeOrder).ID, plotor.OrderStatusFilled)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	assert.Eventually(t, func() bool {
+		b, err := orderer.Bracket(b.ID)
+		return err == nil && errors.Is(b.Err, plotor.ErrBothExitsFilled)
+	}, time.Second, time.Millisecond)
+
+	b, err = orderer.Bracket(b.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, []plotor.BracketStatus{plotor.BracketStatusTakeProfit, plotor.BracketStatusStopLoss}, b.Status)
+}
+
+func TestPlotOrderer_StopBracket(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	orderer, clock := newBracketOrderer(t, client)
+
+	b, err := orderer.CreateBracket(ctx,
+		plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1}},
+		plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: 100}},
+		plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: -100}},
+		time.Minute)
+	assert.NoError(t, err)
+
+	assert.NoError(t, orderer.StopBracket(ctx, b.ID, true))
+	assert.Error(t, orderer.StopBracket(ctx, b.ID, true))
+
+	b, err = orderer.Bracket(b.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.BracketStatusClosed, b.Status)
+
+	entry, err := orderer.Get(ctx, b.EntryID)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.StatusStopped, entry.Status)
+	assert.Equal(t, plotor.OrderStatusCanceled, entry.Order.OrderStatus())
+
+	// the stopped entry does not place the exits
+	clock.Advance(time.Minute)
+	_, err = orderer.Get(ctx, b.TakeProfitID)
+	assert.Error(t, err)
+	assert.Len(t, orderer.Brackets(), 1)
+}
+
+func TestPlotOrderer_CreateBracketInvalid(t *testing.T) {
+	orderer := plotor.NewPlotOrderer(newFakeClient())
+
+	_, err := orderer.CreateBracket(context.Background(),
+		plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1}},
+		plotor.BracketLeg{OrderData: "BTCUSDT"},
+		plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: -100}},
+		time.Minute)
+	assert.Error(t, err)
+	assert.Empty(t, orderer.Brackets())
+	assert.Empty(t, orderer.List(plotor.ListFilter{}))
+}
+
+func TestPlotOrderer_RestoreBracket(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient()
+	orderer, clock := newBracketOrderer(t, client)
+
+	var persisted *plotor.Bracket
+	orderer.OnBracketUpdate(func(b *plotor.Bracket) { persisted = b })
+
+	tpLeg := plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: 100}}
+	slLeg := plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: -100}}
+
+	b, err := orderer.CreateBracket(ctx, plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1}}, tpLeg, slLeg, time.Minute)
+	assert.NoError(t, err)
+
+	client.setStatus(plotor.OrderStatusFilled)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	waitBracketStatus(t, orderer, b.ID, plotor.BracketStatusOpen)
+	assert.Equal(t, plotor.BracketStatusOpen, persisted.Status)
+
+	tp, err := orderer.Get(ctx, b.TakeProfitID)
+	assert.NoError(t, err)
+	sl, err := orderer.Get(ctx, b.StopLossID)
+	assert.NoError(t, err)
+
+	// the exits are resumed by a new orderer as if the process was restarted, the take-profit was filled in the meantime
+	restored, restoredClock := newBracketOrderer(t, client)
+	for _, po := range []*plotor.PlotOrder{tp, sl} {
+		_, err := restored.Restore(ctx, plotor.NewPlotOrder(po.Order, po.Plot, po.Interval, func(r *plotor.PlotOrder) { r.ID = po.ID }))
+		assert.NoError(t, err)
+	}
+
+	client.setOrderStatus(tp.Order.(*fakeOrder).ID, plotor.OrderStatusFilled)
+
+	rb, err := restored.RestoreBracket(ctx, persisted, tpLeg, slLeg)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.BracketStatusOpen, rb.Status)
+
+	_, err = restored.RestoreBracket(ctx, persisted, tpLeg, slLeg)
+	assert.Error(t, err)
+
+	restoredClock.BlockUntil(1)
+	restoredClock.Advance(time.Minute)
+
+	waitBracketStatus(t, restored, b.ID, plotor.BracketStatusTakeProfit)
+
+	assert.Eventually(t, func() bool {
+		po, err := restored.Get(ctx, sl.ID)
+		return err == nil && po.Status == plotor.StatusStopped && po.Order.OrderStatus() == plotor.OrderStatusCanceled
+	}, time.Second, time.Millisecond)
+
+	// an exit that was not restored fails the bracket
+	missing := plotor.NewPlotOrderer(client)
+	rb, err = missing.RestoreBracket(ctx, persisted, tpLeg, slLeg)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.BracketStatusFailed, rb.Status)
+	assert.Error(t, rb.Err)
+}
+
+func TestPlotOrderer_BracketEntryPartiallyFilled(t *testing.T) {
+	tests := []struct {
+		name  string
+		close func(t *testing.T, orderer *plotor.PlotOrderer, client *fakeClient, clock *plotor.ManualClock, entryID string)
+	}{
+		{
+			name: "stopped",
+			close: func(t *testing.T, orderer *plotor.PlotOrderer, client *fakeClient, clock *plotor.ManualClock, entryID string) {
+				assert.NoError(t, orderer.Stop(context.Background(), entryID, true))
+			},
+		},
+		{
+			name: "expired",
+			close: func(t *testing.T, orderer *plotor.PlotOrderer, client *fakeClient, clock *plotor.ManualClock, entryID string) {
+				client.setStatus(plotor.OrderStatusExpired)
+				clock.BlockUntil(1)
+				clock.Advance(time.Minute)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := newFakeClient()
+			orderer, clock := newBracketOrderer(t, client)
+
+			b, err := orderer.CreateBracket(ctx,
+				plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1}},
+				plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: 100}},
+				plotor.BracketLeg{OrderData: "BTCUSDT", Plot: &geometry.Line{A: 1, B: -100}},
+				time.Minute)
+			assert.NoError(t, err)
+
+			client.setStatus(plotor.OrderStatusPartiallyFilled)
+			clock.BlockUntil(1)
+			clock.Advance(time.Minute)
+
+			assert.Eventually(t, func() bool {
+				b, err := orderer.Bracket(b.ID)
+				return err == nil && b.EntryPartiallyFilled
+			}, time.Second, time.Millisecond)
+
+			tt.close(t, orderer, client, clock, b.EntryID)
+
+			waitBracketStatus(t, orderer, b.ID, plotor.BracketStatusFailed)
+
+			b, err = orderer.Bracket(b.ID)
+			assert.NoError(t, err)
+			assert.ErrorIs(t, b.Err, plotor.ErrEntryPartiallyFilled)
+
+			_, err = orderer.Get(ctx, b.TakeProfitID)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package plotor
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a source of time used to schedule plot order ticks, it allows replacing the real time in tests and backtests
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event that happens after a duration, it mirrors time.Timer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// RealClock uses the time package
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// ManualClock is a clock that only moves when it's told to, timers fire once the clock is advanced past their deadline
+type ManualClock struct {
+	now    time.Time
+	timers []*manualTimer
+	// cond is broadcasted every time a timer is added or removed
+	cond *sync.Cond
+	mu   *sync.Mutex
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	mu := &sync.Mutex{}
+
+	return &ManualClock{
+		now:  now,
+		cond: sync.NewCond(mu),
+		mu:   mu,
+	}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer creates a timer firing at Now() + d, a timer with non-positive duration fires right away
+func (c *ManualClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &manualTimer{c: make(chan time.Time, 1), deadline: c.now.Add(d), clock: c}
+
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+
+	return t
+}
+
+// Advance moves the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t and fires all timers with a deadline before or at t in order of their deadlines
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+
+	pending := []*manualTimer{}
+	for _, timer := range c.timers {
+		if timer.deadline.After(t) {
+			pending = append(pending, timer)
+			continue
+		}
+
+		timer.c <- t
+	}
+
+	c.timers = pending
+	c.cond.Broadcast()
+}
+
+// Timers returns the number of timers that did not fire and were not stopped yet
+func (c *ManualClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until there are n pending timers, e.g. until a running plot order starts waiting for the next interval
+func (c *ManualClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) != n {
+		c.cond.Wait()
+	}
+}
+
+type manualTimer struct {
+	c        chan time.Time
+	deadline time.Time
+	clock    *ManualClock
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package plotor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	clock := plotor.NewManualClock(start)
+
+	late := clock.NewTimer(2 * time.Minute)
+	early := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Minute)
+	assert.Equal(t, 3, clock.Timers())
+
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	clock.Advance(90 * time.Second)
+	assert.Equal(t, start.Add(90*time.Second), clock.Now())
+	assert.Equal(t, start.Add(90*time.Second), <-early.C())
+	assert.Empty(t, late.C())
+	assert.Empty(t, stopped.C())
+	assert.Equal(t, 1, clock.Timers())
+
+	clock.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), <-late.C())
+	assert.Equal(t, 0, clock.Timers())
+
+	assert.Equal(t, start.Add(time.Hour), <-clock.NewTimer(0).C())
+}
+
+func TestPlotOrderer_ManualClock(t *testing.T) {
+	start := time.Date(2023, 1, 15, 0, 0, 30, 0, time.UTC)
+	clock := plotor.NewManualClock(start)
+
+	client := newFakeClient()
+	orderer := plotor.NewPlotOrderer(client)
+	scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+	defer scheduler.Stop()
+	orderer.SetScheduler(scheduler)
+
+	// the price is the unix time so every handler invocation tells when it happened
+	po, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Minute)
+	assert.NoError(t, err)
+
+	minute := func(m int) time.Time { return time.Date(2023, 1, 15, 0, m, 0, 0, time.UTC) }
+	prices := []float64{float64(start.Unix())}
+
+	// ticks happen exactly at the interval boundaries
+	for m := 1; m <= 3; m++ {
+		clock.BlockUntil(1)
+		clock.Set(minute(m))
+		clock.BlockUntil(1)
+
+		prices = append(prices, float64(minute(m).Unix()))
+		assert.Equal(t, prices, client.prices)
+		assert.Equal(t, minute(m), po.Snapshot().LastTick)
+		assert.Equal(t, plotor.StatusRunning, po.Snapshot().Status)
+	}
+
+	// missed intervals are skipped, the plot order ticks once and waits for the next boundary
+	clock.Advance(150 * time.Second)
+	clock.BlockUntil(1)
+	assert.Equal(t, append(prices, float64(minute(4).Unix())), client.prices)
+	assert.Equal(t, minute(4), po.Snapshot().LastTick)
+
+	clock.Set(minute(6).Add(-time.Second))
+	assert.Equal(t, 5, client.priceCount())
+
+	assert.NoError(t, orderer.Stop(context.Background(), po.ID, false))
+	clock.BlockUntil(0)
+	assert.Equal(t, plotor.StatusStopped, po.Snapshot().Status)
+}
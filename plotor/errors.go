@@ -1,8 +1,33 @@
 package plotor
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"net"
+)
 
 var (
 	ErrInternalClientError = errors.New("internal client error")
 	ErrInternalError       = errors.New("internal error")
+	// ErrTransient marks client errors that may go away when the request is repeated, e.g. timeouts or overloaded exchange
+	ErrTransient = errors.New("transient error")
+	// ErrPermanent marks client errors that will happen again when the request is repeated, e.g. rejected orders
+	ErrPermanent = errors.New("permanent error")
 )
+
+// IsTransient returns true if the request that returned the error is worth repeating.
+// Errors marked with ErrPermanent or ErrInternalError are never transient, errors marked with ErrTransient or ErrInternalClientError,
+// timeouts and network errors are transient. Unknown errors are treated as permanent.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, ErrPermanent) || errors.Is(err, ErrInternalError) {
+		return false
+	}
+
+	if errors.Is(err, ErrTransient) || errors.Is(err, ErrInternalClientError) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
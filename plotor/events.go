@@ -0,0 +1,165 @@
+package plotor
+
+import (
+	"sync"
+	"time"
+)
+
+// DEFAULT_EVENT_BUFFER is the number of events a subscriber can fall behind before its events are dropped
+const DEFAULT_EVENT_BUFFER = 64
+
+// EventType describes what happened to a plot order
+type EventType string
+
+const (
+	// EventCreated is published when a plot order is created, right before it starts running
+	EventCreated EventType = "CREATED"
+	// EventTriggered is published when the market price crosses the trigger plot and the order is placed
+	EventTriggered EventType = "TRIGGERED"
+	// EventRepriced is published when the order is moved to a new price
+	EventRepriced EventType = "REPRICED"
+	// EventPartiallyFilled is published when the order becomes partially filled
+	EventPartiallyFilled EventType = "PARTIALLY_FILLED"
+	// EventOutOfRange is published when the plot goes out of range, plot orders waiting for the plot to come back publish it once
+	EventOutOfRange EventType = "OUT_OF_RANGE"
+	// EventFilled is published when the plot order stops because the order was filled
+	EventFilled EventType = "FILLED"
+	// EventStopped is published when the plot order is stopped by the user or the order is cancelled or expires on the exchange
+	EventStopped EventType = "STOPPED"
+	// EventError is published when the plot order fails
+	EventError EventType = "ERROR"
+	// EventCrossedAbove is published when the market price of an alert moves above the plot
+	EventCrossedAbove EventType = "CROSSED_ABOVE"
+	// EventCrossedBelow is published when the market price of an alert moves below the plot
+	EventCrossedBelow EventType = "CROSSED_BELOW"
+)
+
+// Event is a change of a plot order pushed to subscribers of the PlotOrderer
+type Event struct {
+	Type        EventType
+	PlotOrderID string
+	Time        time.Time
+	// Status is the status of the plot order after the change
+	Status      Status
+	Symbol      string
+	OrderID     string
+	OrderStatus OrderStatus
+	OrderPrice  float64
+	// MarketPrice is the market price at the last tick of an alert
+	MarketPrice float64 `json:",omitempty"`
+	Error       string  `json:",omitempty"`
+}
+
+// broker fans events out to subscribers, events are dropped for subscribers that do not keep up
+type broker struct {
+	subs   map[int]chan Event
+	nextID int
+	mu     *sync.Mutex
+}
+
+func newBroker() *broker {
+	return &broker{
+		subs: map[int]chan Event{},
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (b *broker) subscribe(buffer int) (<-chan Event, func()) {
+	if buffer <= 0 {
+		buffer = DEFAULT_EVENT_BUFFER
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	events := make(chan Event, buffer)
+	b.subs[id] = events
+
+	once := &sync.Once{}
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subs, id)
+			close(events)
+		})
+	}
+
+	return events, unsubscribe
+}
+
+func (b *broker) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, events := range b.subs {
+		select {
+		case events <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving events of all plot orders of the orderer and a function that unsubscribes and closes the channel.
+// Buffer is the capacity of the channel, DEFAULT_EVENT_BUFFER is used when it's not positive. Events are not delivered while the channel is full.
+func (p *PlotOrderer) Subscribe(buffer int) (<-chan Event, func()) {
+	return p.events.subscribe(buffer)
+}
+
+// event returns an event of the plot order describing its current state
+func (po *PlotOrder) event(typ EventType) Event {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+
+	e := Event{
+		Type:        typ,
+		PlotOrderID: po.ID,
+		Time:        po.clock.Now(),
+		Status:      po.Status,
+		OrderID:     orderID(po.Order),
+	}
+
+	if po.Order != nil {
+		e.Symbol = po.Order.OrderSymbol()
+		e.OrderStatus = po.Order.OrderStatus()
+		e.OrderPrice = po.Order.OrderPrice()
+	}
+
+	if po.Alert != nil {
+		e.Symbol = po.Alert.Symbol
+		e.MarketPrice = po.Alert.MarketPrice
+	}
+
+	if po.Trigger != nil {
+		e.Symbol = po.Trigger.Symbol
+		e.MarketPrice = po.Trigger.MarketPrice
+	}
+
+	if po.Err != nil {
+		e.Error = po.Err.Error()
+	}
+
+	return e
+}
+
+func (po *PlotOrder) publish(typ EventType) {
+	if po.onEvent != nil {
+		po.onEvent(po.event(typ))
+	}
+}
+
+// statusEvent returns the event published when the plot order moves to a status, false if there is none
+func statusEvent(s Status) (EventType, bool) {
+	switch s {
+	case StatusFilled:
+		return EventFilled, true
+	case StatusStopped, StatusCancelled, StatusExpired:
+		return EventStopped, true
+	case StatusFailed:
+		return EventError, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,137 @@
+package plotor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+// eventTypes receives n events and returns their types
+func eventTypes(t *testing.T, events <-chan plotor.Event, n int) []plotor.EventType {
+	t.Helper()
+
+	types := []plotor.EventType{}
+
+	for i := 0; i < n; i++ {
+		select {
+		case e := <-events:
+			types = append(types, e.Type)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d, got %v", i+1, types)
+		}
+	}
+
+	return types
+}
+
+func TestPlotOrderer_Subscribe(t *testing.T) {
+	start := time.Date(2023, 1, 15, 0, 0, 30, 0, time.UTC)
+	clock := plotor.NewManualClock(start)
+	scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+	defer scheduler.Stop()
+
+	client := newFakeClient()
+	orderer := plotor.NewPlotOrderer(client)
+	orderer.SetScheduler(scheduler)
+	assert.Equal(t, start, orderer.Now())
+
+	events, unsubscribe := orderer.Subscribe(0)
+
+	po, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Minute)
+	assert.NoError(t, err)
+
+	created := <-events
+	assert.Equal(t, plotor.Event{
+		Type:        plotor.EventCreated,
+		PlotOrderID: po.ID,
+		Time:        start,
+		Status:      plotor.StatusPending,
+		OrderID:     "1",
+		OrderStatus: plotor.OrderStatusNew,
+		OrderPrice:  float64(start.Unix()),
+	}, created)
+
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, []plotor.EventType{plotor.EventRepriced}, eventTypes(t, events, 1))
+
+	// the order is partially filled before it's re-priced
+	client.setStatus(plotor.OrderStatusPartiallyFilled)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	assert.Equal(t, []plotor.EventType{plotor.EventPartiallyFilled, plotor.EventRepriced}, eventTypes(t, events, 2))
+
+	client.setStatus(plotor.OrderStatusFilled)
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	filled := <-events
+	assert.Equal(t, plotor.EventFilled, filled.Type)
+	assert.Equal(t, plotor.StatusFilled, filled.Status)
+	assert.Equal(t, "3", filled.OrderID)
+
+	unsubscribe()
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+func TestPlotOrderer_SubscribeStopAndError(t *testing.T) {
+	clock := plotor.NewManualClock(time.Date(2023, 1, 15, 0, 0, 30, 0, time.UTC))
+	scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+	defer scheduler.Stop()
+
+	client := newFakeClient()
+	client.updateErr = errors.New("boom")
+	orderer := plotor.NewPlotOrderer(client)
+	orderer.SetScheduler(scheduler)
+
+	events, unsubscribe := orderer.Subscribe(0)
+	defer unsubscribe()
+
+	stopped, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, orderer.Stop(context.Background(), stopped.ID, false))
+	assert.Equal(t, []plotor.EventType{plotor.EventCreated, plotor.EventStopped}, eventTypes(t, events, 2))
+
+	failed, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Minute, plotor.WithRetry(plotor.RetryPolicy{MaxAttempts: 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, []plotor.EventType{plotor.EventCreated}, eventTypes(t, events, 1))
+
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+
+	e := <-events
+	assert.Equal(t, plotor.EventError, e.Type)
+	assert.Equal(t, failed.ID, e.PlotOrderID)
+	assert.Equal(t, plotor.StatusFailed, e.Status)
+	assert.Equal(t, "order was cancelled but not replaced: boom", e.Error)
+}
+
+func TestPlotOrderer_SubscribeOutOfRange(t *testing.T) {
+	minute := func(m int) time.Time { return time.Date(2023, 1, 15, 0, m, 0, 0, time.UTC) }
+	clock := plotor.NewManualClock(minute(0).Add(30 * time.Second))
+	scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+	defer scheduler.Stop()
+
+	orderer := plotor.NewPlotOrderer(newFakeClient())
+	orderer.SetScheduler(scheduler)
+
+	events, unsubscribe := orderer.Subscribe(0)
+	defer unsubscribe()
+
+	// the event is published once while the plot order waits for the plot to come back
+	_, err := orderer.Create(context.Background(), nil, gap{From: minute(1), To: minute(3)}, time.Minute, plotor.WithOutOfRange(plotor.OutOfRangeWait))
+	assert.NoError(t, err)
+
+	for m := 1; m <= 3; m++ {
+		clock.BlockUntil(1)
+		clock.Set(minute(m))
+	}
+
+	assert.Equal(t, []plotor.EventType{plotor.EventCreated, plotor.EventOutOfRange, plotor.EventRepriced}, eventTypes(t, events, 3))
+}
@@ -0,0 +1,112 @@
+package plotor
+
+import (
+	"time"
+)
+
+// DEFAULT_HISTORY_SIZE is the number of tick records kept by a plot order unless it's changed with WithHistorySize
+const DEFAULT_HISTORY_SIZE = 100
+
+// TickRecord describes what happened to the plot order at a single tick
+type TickRecord struct {
+	Time time.Time
+	// PlotPrice is the price of the plot at Time, it's zero when the plot was out of range
+	PlotPrice float64
+	// OrderPrice is the price of the order after the tick, i.e. the plot price after it was filtered by the client
+	OrderPrice float64
+	// MarketPrice is the market price compared to the plot price by an alert
+	MarketPrice float64 `json:",omitempty"`
+	PrevOrderID string
+	OrderID     string
+	// Status is the status of the plot order after the tick
+	Status      Status
+	OrderStatus OrderStatus
+	// Skipped is true when the order was not re-priced because of the update policy
+	Skipped bool
+	Error   string `json:",omitempty"`
+	// Latency is the time it took to handle the tick including retries
+	Latency time.Duration
+}
+
+// History holds tick records of a plot order from the oldest to the most recent one
+type History []TickRecord
+
+// HistoryFilter narrows down the tick records returned by History.Filter, empty fields match every record
+type HistoryFilter struct {
+	// Since excludes records of ticks before this time
+	Since time.Time
+	// Errors excludes records of ticks without an error
+	Errors bool
+	// Limit is the maximum number of the most recent records
+	Limit int
+}
+
+func (f HistoryFilter) match(rec TickRecord) bool {
+	if !f.Since.IsZero() && rec.Time.Before(f.Since) {
+		return false
+	}
+
+	return !f.Errors || rec.Error != ""
+}
+
+// Filter returns a copy of the records matching the filter
+func (h History) Filter(filter HistoryFilter) History {
+	filtered := History{}
+
+	for _, rec := range h {
+		if filter.match(rec) {
+			filtered = append(filtered, rec)
+		}
+	}
+
+	if filter.Limit > 0 && len(filtered) > filter.Limit {
+		filtered = filtered[len(filtered)-filter.Limit:]
+	}
+
+	return filtered
+}
+
+// WithHistorySize sets the number of tick records kept by the plot order, the oldest records are dropped first.
+// History is not recorded when the size is negative.
+func WithHistorySize(size int) Option {
+	return func(po *PlotOrder) {
+		po.HistorySize = size
+	}
+}
+
+// record appends the record to the history dropping the oldest records above the history size
+func (po *PlotOrder) record(rec TickRecord) {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+
+	if po.HistorySize < 0 {
+		return
+	}
+
+	po.History = append(po.History, rec)
+
+	if over := len(po.History) - po.HistorySize; over > 0 {
+		po.History = append(History{}, po.History[over:]...)
+	}
+}
+
+// History returns the tick records of the plot order matching the filter
+func (p *PlotOrderer) History(plotOrderID string, filter HistoryFilter) (History, error) {
+	po, err := p.plotOrder(plotOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	po.mu.Lock()
+	defer po.mu.Unlock()
+
+	return po.History.Filter(filter), nil
+}
+
+func orderID(order ClientOrder) string {
+	if order == nil {
+		return ""
+	}
+
+	return order.ExchangeOrderID()
+}
@@ -0,0 +1,95 @@
+package plotor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_Filter(t *testing.T) {
+	minute := func(m int) time.Time { return time.Date(2023, 1, 15, 0, m, 0, 0, time.UTC) }
+
+	history := plotor.History{
+		{Time: minute(1)},
+		{Time: minute(2), Error: "boom"},
+		{Time: minute(3)},
+		{Time: minute(4), Error: "boom"},
+	}
+
+	assert.Equal(t, history, history.Filter(plotor.HistoryFilter{}))
+	assert.Equal(t, history[2:], history.Filter(plotor.HistoryFilter{Since: minute(3)}))
+	assert.Equal(t, plotor.History{history[1], history[3]}, history.Filter(plotor.HistoryFilter{Errors: true}))
+	assert.Equal(t, history[3:], history.Filter(plotor.HistoryFilter{Errors: true, Limit: 1}))
+	assert.Equal(t, plotor.History{}, history.Filter(plotor.HistoryFilter{Since: minute(5)}))
+}
+
+func TestPlotOrderer_History(t *testing.T) {
+	start := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+	clock := plotor.NewManualClock(start)
+	scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+	defer scheduler.Stop()
+
+	client := newFakeClient()
+	orderer := plotor.NewPlotOrderer(client)
+	orderer.SetScheduler(scheduler)
+
+	// the price starts at 100 and rises by 1 every minute
+	rising := &geometry.Line{A: 1.0 / 60, B: 100 - float64(start.Unix())/60}
+
+	po, err := orderer.Create(context.Background(), nil, rising, time.Minute,
+		plotor.WithHistorySize(3),
+		plotor.WithUpdatePolicy(plotor.UpdatePolicy{MinChange: 1.5}),
+		plotor.WithRetry(plotor.RetryPolicy{MaxAttempts: 1}),
+	)
+	assert.NoError(t, err)
+
+	for m := 1; m <= 3; m++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+	}
+
+	client.updateErr = errors.New("boom")
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool { return po.Snapshot().Status == plotor.StatusFailed }, time.Second, time.Millisecond)
+
+	history, err := orderer.History(po.ID, plotor.HistoryFilter{})
+	assert.NoError(t, err)
+
+	// the first tick was dropped, the order was re-priced to 102 at the second one and left there at the third one
+	assert.Len(t, history, 3)
+	for i := range history {
+		history[i].Latency = 0
+	}
+
+	assert.InDelta(t, 102.0, history[0].PlotPrice, 1e-9)
+	assert.InDelta(t, 102.0, history[0].OrderPrice, 1e-9)
+	history[0].PlotPrice, history[0].OrderPrice = 0, 0
+
+	assert.InDelta(t, 103.0, history[1].PlotPrice, 1e-9)
+	assert.InDelta(t, 102.0, history[1].OrderPrice, 1e-9)
+	history[1].PlotPrice, history[1].OrderPrice = 0, 0
+
+	assert.InDelta(t, 104.0, history[2].PlotPrice, 1e-9)
+	assert.InDelta(t, 102.0, history[2].OrderPrice, 1e-9)
+	history[2].PlotPrice, history[2].OrderPrice = 0, 0
+
+	assert.Equal(t, plotor.History{
+		{Time: start.Add(2 * time.Minute), PrevOrderID: "1", OrderID: "2", Status: plotor.StatusRunning, OrderStatus: plotor.OrderStatusNew},
+		{Time: start.Add(3 * time.Minute), PrevOrderID: "2", OrderID: "2", Status: plotor.StatusRunning, OrderStatus: plotor.OrderStatusNew, Skipped: true},
+		{Time: start.Add(4 * time.Minute), PrevOrderID: "2", OrderID: "2", Status: plotor.StatusFailed, OrderStatus: plotor.OrderStatusCanceled, Error: "order was cancelled but not replaced: boom"},
+	}, history)
+
+	errs, err := orderer.History(po.ID, plotor.HistoryFilter{Errors: true})
+	assert.NoError(t, err)
+	assert.Len(t, errs, 1)
+
+	_, err = orderer.History("missing", plotor.HistoryFilter{})
+	assert.Error(t, err)
+}
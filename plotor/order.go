@@ -1,14 +1,28 @@
 package plotor
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/H3Cki/Plotor/geometry"
 	"github.com/google/uuid"
 )
 
+// errOrderClosed is returned by a handler when the order can no longer be updated, it stops the plot order without an error
+var errOrderClosed = errors.New("order closed")
+
 type ClientOrder interface {
 	Details() (map[string]any, error)
+	// OrderStatus returns the status of the order on the exchange
+	OrderStatus() OrderStatus
+	// ExchangeOrderID returns the ID of the order on the exchange
+	ExchangeOrderID() string
+	// OrderSymbol returns the symbol the order was placed for
+	OrderSymbol() string
+	// OrderPrice returns the price of the order, zero if it's unknown
+	OrderPrice() float64
 }
 
 type Handler func(order ClientOrder, price float64) error
@@ -16,66 +30,418 @@ type Handler func(order ClientOrder, price float64) error
 // PlotOrder aggregates neccessary information and uses it to update the order
 type PlotOrder struct {
 	ID       string
-	IsActive bool
+	Status   Status
+	Err      error
 	Plot     geometry.Plot
 	Interval time.Duration
+	// Order is the order on the exchange, it's nil when the plot order is an alert
 	Order    ClientOrder
 	LastTick time.Time
-	stopC    chan struct{}
-	ticker   *time.Ticker
+	// Alert is set when the plot order is an alert, see PlotOrderer.CreateAlert
+	Alert *Alert
+	// Trigger delays placing the order until the market price crosses the trigger plot, see WithTrigger
+	Trigger *Trigger
+	// Retry controls repeating updates that failed with a transient error
+	Retry RetryPolicy
+	// OutOfRange decides what happens to the order when the plot goes out of range
+	OutOfRange OutOfRangePolicy
+	// UpdatePolicy decides when re-pricing the order can be skipped
+	UpdatePolicy UpdatePolicy
+	// SkippedTicks is the number of ticks at which the order was not re-priced because of the update policy
+	SkippedTicks int
+	// waiting is true while a plot order with OutOfRangeWait policy waits for the plot to come back in range
+	waiting bool
+	// History holds the records of the most recent ticks
+	History History
+	// HistorySize is the maximum number of records in History
+	HistorySize int
+	// exit cancels the order or replaces it with a market order, it's set by the PlotOrderer
+	exit func(policy OutOfRangePolicy) (ClientOrder, error)
+	// activate checks the trigger and places the order once it's crossed, it's set by the PlotOrderer
+	activate func(t time.Time) (bool, error)
+	// clock schedules the ticks of Run and RunNextInterval, plot orders started by a PlotOrderer are ticked by its Scheduler instead
+	clock Clock
+	// stopC is closed when the plot order leaves an active status, it's recreated when the plot order is resumed
+	stopC chan struct{}
+	// resetC notifies the running plot order that the interval has changed
+	resetC   chan struct{}
+	onUpdate func(po *PlotOrder)
+	// onEvent publishes events of the plot order, it's set by the PlotOrderer
+	onEvent func(e Event)
+	// tickMu makes sure that the order is not updated by two ticks at once
+	tickMu *sync.Mutex
+	mu     *sync.Mutex
 }
 
-func NewPlotOrder(order ClientOrder, plot geometry.Plot, interval time.Duration) *PlotOrder {
-	return &PlotOrder{
-		ID:       uuid.NewString(),
-		Plot:     plot,
-		Interval: interval,
+func NewPlotOrder(order ClientOrder, plot geometry.Plot, interval time.Duration, opts ...Option) *PlotOrder {
+	po := &PlotOrder{
+		ID:          uuid.NewString(),
+		Status:      StatusPending,
+		Plot:        plot,
+		Interval:    interval,
+		Retry:       DefaultRetryPolicy,
+		OutOfRange:  OutOfRangeLeave,
+		HistorySize: DEFAULT_HISTORY_SIZE,
+
+		Order:  order,
+		clock:  RealClock{},
+		stopC:  make(chan struct{}),
+		resetC: make(chan struct{}, 1),
+		tickMu: &sync.Mutex{},
+		mu:     &sync.Mutex{},
+	}
 
-		Order: order,
-		stopC: make(chan struct{}),
+	for _, opt := range opts {
+		opt(po)
 	}
+
+	return po
 }
 
 // Run starts ticking the price on the plot every given interval and passing it to the handler
 func (po *PlotOrder) Run(handler Handler) error {
-	return po.run(time.Now(), handler)
+	return po.run(po.clock.Now(), handler)
 }
 
 // RunNextInterval waits until the start of the next interval to start running
 func (po *PlotOrder) RunNextInterval(handler Handler) error {
-	t := <-time.After(time.Until(NextIntervalStart(time.Now(), po.Interval)))
+	t, ok := po.wait(po.stopChan())
+	if !ok {
+		return nil
+	}
+
 	return po.run(t, handler)
 }
 
 func (po *PlotOrder) run(t time.Time, handler Handler) error {
-	po.ticker = time.NewTicker(po.Interval)
-	po.IsActive = true
-	defer func() {
-		po.ticker.Stop()
-		po.IsActive = false
-	}()
+	stopC := po.stopChan()
+
+	if err := po.transition(StatusRunning, nil); err != nil {
+		// the plot order was paused or stopped in the meantime
+		return nil
+	}
 
 	for {
-		price, err := po.Plot.At(t)
-		if err != nil {
+		if ok, err := po.tick(t, handler); !ok {
 			return err
 		}
 
-		if err := handler(po.Order, price); err != nil {
-			return err
+		next, ok := po.wait(stopC)
+		if !ok {
+			return nil
 		}
 
+		t = next
+	}
+}
+
+// tick passes the price of the plot at time t to the handler and records the outcome in the history,
+// it returns false when the plot order should stop running
+func (po *PlotOrder) tick(t time.Time, handler Handler) (bool, error) {
+	po.tickMu.Lock()
+	defer po.tickMu.Unlock()
+
+	start := po.clock.Now()
+	rec := TickRecord{Time: t, PrevOrderID: orderID(po.order())}
+
+	ok, err := po.handle(t, handler, &rec)
+
+	order := po.order()
+	rec.OrderID = orderID(order)
+	if order != nil {
+		rec.OrderPrice = order.OrderPrice()
+		rec.OrderStatus = order.OrderStatus()
+	}
+
+	if alert := po.alert(); alert != nil {
+		rec.MarketPrice = alert.MarketPrice
+	}
+
+	rec.Status = po.status()
+	rec.Latency = po.clock.Now().Sub(start)
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	po.record(rec)
+
+	// plot orders that stopped were already updated when they changed the status
+	if ok {
+		po.update()
+	}
+
+	if ok && rec.Error == "" && !rec.Skipped && order != nil {
+		po.publish(EventRepriced)
+	}
+
+	return ok, err
+}
+
+// handle does the work of a single tick, the record is filled with the plot price and how the tick was handled
+func (po *PlotOrder) handle(t time.Time, handler Handler, rec *TickRecord) (bool, error) {
+	price, err := po.plot().At(t)
+	if errors.Is(err, geometry.ErrOutOfRange) {
+		rec.Error = err.Error()
+		return po.outOfRange(err)
+	}
+
+	rec.PlotPrice = price
+
+	po.mu.Lock()
+	po.waiting = false
+	po.mu.Unlock()
+
+	if err != nil {
+		po.finish(StatusFailed, err)
+		return false, err
+	}
+
+	err = handler(po.order(), price)
+	if errors.Is(err, errOrderClosed) {
+		po.finish(statusFromOrder(po.order().OrderStatus()), nil)
+		return false, nil
+	}
+
+	if errors.Is(err, errUpdateSkipped) {
+		rec.Skipped = true
+
+		po.mu.Lock()
+		po.SkippedTicks++
 		po.LastTick = t
+		po.mu.Unlock()
+
+		return true, nil
+	}
+
+	if err != nil {
+		po.finish(StatusFailed, err)
+		return false, err
+	}
+
+	po.mu.Lock()
+	po.LastTick = t
+	po.mu.Unlock()
+
+	return true, nil
+}
+
+// step runs a single tick scheduled at time t, a pending plot order moves to running on its first tick
+// unless it waits for its trigger.
+// It returns false when the plot order should not be scheduled again.
+func (po *PlotOrder) step(t time.Time, handler Handler) (bool, error) {
+	switch po.status() {
+	case StatusPending:
+		if po.armed() {
+			return po.check(t)
+		}
+
+		if err := po.transition(StatusRunning, nil); err != nil {
+			// the plot order was paused or stopped in the meantime
+			return false, nil
+		}
+	case StatusRunning:
+	default:
+		return false, nil
+	}
+
+	return po.tick(t, handler)
+}
+
+// wait blocks until the start of the next interval and returns its time, it returns false if the plot order was stopped.
+// The wait starts over when the interval is changed.
+func (po *PlotOrder) wait(stopC chan struct{}) (time.Time, bool) {
+	for {
+		now := po.clock.Now()
+		next := NextIntervalStart(now, po.interval())
+		timer := po.clock.NewTimer(next.Sub(now))
 
 		select {
-		case <-po.stopC:
-			return nil
-		case tick := <-po.ticker.C:
-			t = tick
+		case <-stopC:
+			timer.Stop()
+			return time.Time{}, false
+		case <-po.resetC:
+			timer.Stop()
+		case <-timer.C():
+			return next, true
 		}
 	}
 }
 
+// Stop moves the plot order to the stopped status, stopping a plot order that is already in a terminal status does nothing
 func (po *PlotOrder) Stop() {
-	close(po.stopC)
+	po.mu.Lock()
+	terminal := po.Status.Terminal()
+	po.mu.Unlock()
+
+	if terminal {
+		return
+	}
+
+	_ = po.transition(StatusStopped, nil)
+}
+
+// Pause stops updating the order until the plot order is resumed
+func (po *PlotOrder) Pause() error {
+	return po.transition(StatusPaused, nil)
+}
+
+// resume moves a paused plot order back to pending, it has to be run again afterwards
+func (po *PlotOrder) resume() error {
+	return po.transition(StatusPending, nil)
+}
+
+// Snapshot returns a copy of the plot order that is safe to read while the plot order is running
+func (po *PlotOrder) Snapshot() *PlotOrder {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+
+	return &PlotOrder{
+		ID:           po.ID,
+		Status:       po.Status,
+		Err:          po.Err,
+		Plot:         po.Plot,
+		Interval:     po.Interval,
+		Order:        po.Order,
+		LastTick:     po.LastTick,
+		Alert:        po.Alert.copy(),
+		Trigger:      po.Trigger.copy(),
+		Retry:        po.Retry,
+		OutOfRange:   po.OutOfRange,
+		UpdatePolicy: po.UpdatePolicy,
+		SkippedTicks: po.SkippedTicks,
+		History:      append(History(nil), po.History...),
+		HistorySize:  po.HistorySize,
+		clock:        po.clock,
+		stopC:        make(chan struct{}),
+		resetC:       make(chan struct{}, 1),
+		tickMu:       &sync.Mutex{},
+		mu:           &sync.Mutex{},
+	}
+}
+
+// swap replaces the plot and/or the interval, nil plot and zero interval are left unchanged.
+// A running plot order realigns its ticks to the new interval.
+func (po *PlotOrder) swap(plot geometry.Plot, interval time.Duration) {
+	po.mu.Lock()
+	if plot != nil {
+		po.Plot = plot
+	}
+
+	intervalChanged := interval != 0 && interval != po.Interval
+	if intervalChanged {
+		po.Interval = interval
+	}
+	po.mu.Unlock()
+
+	if intervalChanged {
+		select {
+		case po.resetC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// finish moves the plot order to a terminal status, it does nothing if the plot order was stopped or paused in the meantime
+func (po *PlotOrder) finish(status Status, err error) {
+	_ = po.transition(status, err)
+}
+
+// transition moves the plot order to a new status, the stop channel is closed when the plot order stops being active
+// and recreated when it becomes active again
+func (po *PlotOrder) transition(to Status, err error) error {
+	po.mu.Lock()
+
+	from := po.Status
+	if !from.CanTransition(to) {
+		po.mu.Unlock()
+		return fmt.Errorf("plot order %s can not transition from %s to %s", po.ID, from, to)
+	}
+
+	if from.Active() && !to.Active() {
+		close(po.stopC)
+	}
+
+	if !from.Active() && to.Active() {
+		po.stopC = make(chan struct{})
+	}
+
+	po.Status = to
+	po.Err = err
+	po.mu.Unlock()
+
+	po.update()
+
+	if typ, ok := statusEvent(to); ok {
+		po.publish(typ)
+	}
+
+	return nil
+}
+
+func (po *PlotOrder) stopChan() chan struct{} {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return po.stopC
+}
+
+func (po *PlotOrder) status() Status {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return po.Status
+}
+
+func (po *PlotOrder) plot() geometry.Plot {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return po.Plot
+}
+
+func (po *PlotOrder) interval() time.Duration {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return po.Interval
+}
+
+func (po *PlotOrder) order() ClientOrder {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return po.Order
+}
+
+// symbol returns the symbol of the alert or the order
+func (po *PlotOrder) symbol() string {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+
+	if po.Alert != nil {
+		return po.Alert.Symbol
+	}
+
+	if po.Order == nil {
+		if po.Trigger != nil {
+			return po.Trigger.Symbol
+		}
+
+		return ""
+	}
+
+	return po.Order.OrderSymbol()
+}
+
+// setOrder replaces the order, EventPartiallyFilled is published when the order becomes partially filled
+func (po *PlotOrder) setOrder(order ClientOrder) {
+	po.mu.Lock()
+	partial := order.OrderStatus() == OrderStatusPartiallyFilled && (po.Order == nil || po.Order.OrderStatus() != OrderStatusPartiallyFilled)
+	po.Order = order
+	po.mu.Unlock()
+
+	if partial {
+		po.publish(EventPartiallyFilled)
+	}
+}
+
+func (po *PlotOrder) update() {
+	if po.onUpdate != nil {
+		po.onUpdate(po.Snapshot())
+	}
 }
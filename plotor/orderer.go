@@ -2,17 +2,20 @@ package plotor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/H3Cki/Plotor/geometry"
-	"github.com/H3Cki/Plotor/logger"
 )
 
 type Client interface {
 	CreateOrder(ctx context.Context, orderData any, price float64) (order ClientOrder, err error)
 	GetOrder(ctx context.Context, order ClientOrder) (newOrder ClientOrder, err error)
+	// UpdateOrderPrice replaces the order with one placed at given price, the order is cancelled before the new one is created
+	// and a cancelled order is only re-created for its remaining quantity, so a replacement that failed can be repeated
 	UpdateOrderPrice(ctx context.Context, order ClientOrder, price float64) (newOrder ClientOrder, err error)
 	CancelOrder(ctx context.Context, order ClientOrder) (err error)
 }
@@ -21,13 +24,22 @@ type Client interface {
 type PlotOrderer struct {
 	client     Client
 	plotOrders map[string]*PlotOrder
-	mu         *sync.Mutex
+	brackets   map[string]*Bracket
+	onUpdate   func(po *PlotOrder)
+	// onBracketUpdate is called when a bracket changes, see OnBracketUpdate
+	onBracketUpdate func(b *Bracket)
+	events          *broker
+	scheduler       *Scheduler
+	mu              *sync.Mutex
 }
 
 func NewPlotOrderer(c Client) *PlotOrderer {
 	return &PlotOrderer{
 		client:     c,
 		plotOrders: map[string]*PlotOrder{},
+		brackets:   map[string]*Bracket{},
+		events:     newBroker(),
+		scheduler:  DefaultScheduler(),
 		mu:         &sync.Mutex{},
 	}
 }
@@ -36,6 +48,22 @@ func (p *PlotOrderer) Client() Client {
 	return p.client
 }
 
+// OnUpdate sets a function that is called after every successful update of any plot order
+// and when a plot order reaches a terminal status, it can be used to persist the state of plot orders
+func (p *PlotOrderer) OnUpdate(f func(po *PlotOrder)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onUpdate = f
+}
+
+// SetScheduler replaces the default scheduler, its clock is also used to price new plot orders.
+// It has to be called before any plot order is started.
+func (p *PlotOrderer) SetScheduler(s *Scheduler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scheduler = s
+}
+
 // Get returns a copy of the PlotOrder with up-to-date Order fetched from the client
 func (p *PlotOrderer) Get(ctx context.Context, plotOrderID string) (*PlotOrder, error) {
 	p.mu.Lock()
@@ -46,49 +74,245 @@ func (p *PlotOrderer) Get(ctx context.Context, plotOrderID string) (*PlotOrder,
 		return nil, fmt.Errorf("plot order %s not found", plotOrderID)
 	}
 
-	order, err := p.client.GetOrder(ctx, po.Order)
+	// alerts have no order to fetch
+	if po.order() == nil {
+		return po.Snapshot(), nil
+	}
+
+	order, err := p.client.GetOrder(ctx, po.order())
 	if err != nil {
 		return nil, fmt.Errorf("error getting order from client: %w", err)
 	}
 
-	return &PlotOrder{
-		ID:       po.ID,
-		IsActive: po.IsActive,
-		Plot:     po.Plot,
-		Interval: po.Interval,
-		Order:    order,
-		LastTick: po.LastTick,
-		stopC:    make(chan struct{}),
-	}, nil
+	snapshot := po.Snapshot()
+	snapshot.Order = order
+
+	return snapshot, nil
+}
+
+// ListFilter narrows down the plot orders returned by List, empty fields match every plot order
+type ListFilter struct {
+	Statuses []Status
+	Symbol   string
+}
+
+func (f ListFilter) match(po *PlotOrder) bool {
+	if f.Symbol != "" && po.symbol() != f.Symbol {
+		return false
+	}
+
+	if len(f.Statuses) == 0 {
+		return true
+	}
+
+	for _, status := range f.Statuses {
+		if po.Status == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// List returns copies of plot orders matching the filter sorted by ID,
+// orders are not fetched from the client so they reflect the state from the last update
+func (p *PlotOrderer) List(filter ListFilter) []*PlotOrder {
+	p.mu.Lock()
+	plotOrders := make([]*PlotOrder, 0, len(p.plotOrders))
+	for _, po := range p.plotOrders {
+		plotOrders = append(plotOrders, po)
+	}
+	p.mu.Unlock()
+
+	list := []*PlotOrder{}
+
+	for _, po := range plotOrders {
+		snapshot := po.Snapshot()
+		if filter.match(snapshot) {
+			list = append(list, snapshot)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	return list
 }
 
-// Create creates a plot order and updates it continuously until the plot goes out of range or there is an error
-func (p *PlotOrderer) Create(ctx context.Context, orderData any, plot geometry.Plot, interval time.Duration) (*PlotOrder, error) {
-	price, err := plot.At(time.Now())
+// Create creates a plot order and updates it continuously until the plot goes out of range or there is an error.
+// Plot orders with a trigger (see WithTrigger) stay pending and place the order once the trigger is crossed.
+func (p *PlotOrderer) Create(ctx context.Context, orderData any, plot geometry.Plot, interval time.Duration, opts ...Option) (*PlotOrder, error) {
+	price, err := plot.At(p.Now())
 	if err != nil {
 		return nil, err
 	}
 
+	po := NewPlotOrder(nil, plot, interval, opts...)
+
+	if err := po.OutOfRange.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := po.UpdatePolicy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.client.(MarketClient); po.OutOfRange == OutOfRangeMarket && !ok {
+		return nil, errors.New("client does not support market orders")
+	}
+
+	if po.Trigger != nil {
+		return p.createTriggered(ctx, po, orderData)
+	}
+
 	order, err := p.client.CreateOrder(ctx, orderData, price)
 	if err != nil {
 		return nil, err
 	}
 
-	po := NewPlotOrder(order, plot, interval)
+	po.Order = order
+	p.register(ctx, po)
+	po.publish(EventCreated)
+	p.run(ctx, po)
+
+	return po, nil
+}
+
+// Restore resumes a plot order that was created before, e.g. by a previous process.
+// The order is fetched from the client to get its current state and the updates start at the next interval,
+// unless the plot order was paused in which case it stays paused.
+func (p *PlotOrderer) Restore(ctx context.Context, po *PlotOrder) (*PlotOrder, error) {
+	if po.Status != StatusPending && po.Status != StatusPaused {
+		return nil, fmt.Errorf("plot order with status %s can not be restored", po.Status)
+	}
 
 	p.mu.Lock()
-	p.plotOrders[po.ID] = po
+	_, ok := p.plotOrders[po.ID]
 	p.mu.Unlock()
 
-	go func() {
-		if err := po.RunNextInterval(p.handler(ctx, po)); err != nil {
-			logger.Errorf("error updating plot order: %v", err)
+	if ok {
+		return nil, fmt.Errorf("plot order %s already exists", po.ID)
+	}
+
+	if po.Alert != nil || po.Order == nil && po.armed() {
+		if _, ok := p.client.(PriceClient); !ok {
+			return nil, errors.New("client does not support market prices")
 		}
-	}()
+
+		p.start(ctx, po)
+
+		return po, nil
+	}
+
+	order, err := p.client.GetOrder(ctx, po.Order)
+	if err != nil {
+		return nil, fmt.Errorf("error getting order from client: %w", err)
+	}
+
+	po.Order = order
+	p.start(ctx, po)
 
 	return po, nil
 }
 
+// Update replaces the plot and/or the interval of a plot order while keeping its order, nil plot and zero interval are left unchanged.
+// Pending and running plot orders are re-priced right away using the new plot.
+func (p *PlotOrderer) Update(ctx context.Context, plotOrderID string, plot geometry.Plot, interval time.Duration) (*PlotOrder, error) {
+	if plot == nil && interval == 0 {
+		return nil, errors.New("nothing to update")
+	}
+
+	if interval != 0 && interval < time.Second {
+		return nil, fmt.Errorf("interval must be at least 1s, got %s", interval)
+	}
+
+	po, err := p.plotOrder(plotOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := po.Snapshot().Status
+	if status.Terminal() {
+		return nil, fmt.Errorf("plot order %s can not be updated: %s", plotOrderID, status)
+	}
+
+	now := p.Now()
+
+	// make sure the new plot is usable before replacing the current one
+	if plot != nil {
+		if _, err := plot.At(now); err != nil {
+			return nil, fmt.Errorf("error getting price from the new plot: %w", err)
+		}
+	}
+
+	po.swap(plot, interval)
+
+	// the new plot and interval are persisted even if the order is not re-priced below
+	po.update()
+
+	// plot orders waiting for their trigger have no order to re-price
+	if !status.Active() || po.armed() {
+		return po.Snapshot(), nil
+	}
+
+	ok, err := po.tick(now, p.handler(ctx, po))
+	if err != nil {
+		return nil, fmt.Errorf("error updating order price: %w", err)
+	}
+
+	// realign the updates to the new interval
+	if ok && interval != 0 {
+		p.sched().Schedule(po, p.handler(ctx, po))
+	}
+
+	return po.Snapshot(), nil
+}
+
+// Pause stops updating the plot order without cancelling the order, it can be resumed later
+func (p *PlotOrderer) Pause(plotOrderID string) error {
+	po, err := p.plotOrder(plotOrderID)
+	if err != nil {
+		return err
+	}
+
+	if err := po.Pause(); err != nil {
+		return err
+	}
+
+	p.sched().Unschedule(po.ID)
+
+	return nil
+}
+
+// Resume starts updating a paused plot order again at the start of the next interval,
+// the order is fetched from the client first in case it was closed while the plot order was paused
+func (p *PlotOrderer) Resume(ctx context.Context, plotOrderID string) error {
+	po, err := p.plotOrder(plotOrderID)
+	if err != nil {
+		return err
+	}
+
+	if status := po.Snapshot().Status; status != StatusPaused {
+		return fmt.Errorf("plot order %s is not paused: %s", plotOrderID, status)
+	}
+
+	if po.order() != nil {
+		order, err := p.client.GetOrder(ctx, po.order())
+		if err != nil {
+			return fmt.Errorf("error getting order from client: %w", err)
+		}
+
+		po.setOrder(order)
+	}
+
+	if err := po.resume(); err != nil {
+		return err
+	}
+
+	p.run(ctx, po)
+
+	return nil
+}
+
 // Stop stops updating the plot order, if cancelOrder is true then it also cancels the order on the exchange
 func (p *PlotOrderer) Stop(ctx context.Context, plotOrderID string, cancelOrder bool) error {
 	p.mu.Lock()
@@ -109,17 +333,137 @@ func (p *PlotOrderer) StopAll(ctx context.Context, cancelOrder bool) error {
 	return nil
 }
 
+func (p *PlotOrderer) plotOrder(plotOrderID string) (*PlotOrder, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	po, ok := p.plotOrders[plotOrderID]
+	if !ok {
+		return nil, fmt.Errorf("plot order %s not found", plotOrderID)
+	}
+
+	return po, nil
+}
+
+func (p *PlotOrderer) sched() *Scheduler {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.scheduler
+}
+
+// Now returns the current time of the scheduler's clock, it's the time plots of the plot orders are priced at
+func (p *PlotOrderer) Now() time.Time {
+	return p.sched().Clock().Now()
+}
+
+// start registers the plot order and runs it unless it's paused
+func (p *PlotOrderer) start(ctx context.Context, po *PlotOrder) {
+	p.register(ctx, po)
+
+	if po.Snapshot().Status == StatusPaused {
+		return
+	}
+
+	p.run(ctx, po)
+}
+
+// register adds the plot order to the orderer and sets its hooks
+func (p *PlotOrderer) register(ctx context.Context, po *PlotOrder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	po.onUpdate = p.onUpdate
+	po.onEvent = p.publish
+	po.clock = p.scheduler.Clock()
+	po.exit = p.exit(ctx, po)
+	po.activate = p.activate(ctx, po)
+	p.plotOrders[po.ID] = po
+}
+
+// run schedules updates of the plot order starting at the next interval
+func (p *PlotOrderer) run(ctx context.Context, po *PlotOrder) {
+	// the order might have been filled right away or closed while the plot order was not running
+	if order := po.order(); order != nil && order.OrderStatus().Closed() {
+		po.finish(statusFromOrder(order.OrderStatus()), nil)
+		return
+	}
+
+	p.sched().Schedule(po, p.handler(ctx, po))
+}
+
+// handler updates the price of the order, transient errors are retried according to the retry policy of the plot order.
+// Alerts are handled by alertHandler.
 func (p *PlotOrderer) handler(ctx context.Context, po *PlotOrder) Handler {
-	return func(order ClientOrder, price float64) error {
-		newOrder, err := p.client.UpdateOrderPrice(ctx, order, price)
-		if err != nil {
+	if po.alert() != nil {
+		return p.alertHandler(ctx, po)
+	}
+
+	return func(_ ClientOrder, price float64) error {
+		// cancelled is set when an attempt cancelled the order but failed to create the new one
+		cancelled := false
+
+		return po.retry(func() error {
+			// the order could have been replaced by a previous attempt
+			err := p.update(ctx, po, po.order(), price, cancelled)
+			if errors.Is(err, errNotReplaced) {
+				cancelled = true
+			}
+
+			return err
+		})
+	}
+}
+
+// errNotReplaced is returned by update when the order was cancelled by the client but the new order could not be created
+var errNotReplaced = errors.New("order was cancelled but not replaced")
+
+// update updates the price of the order unless it was closed on the exchange in the meantime,
+// errOrderClosed is returned when the plot order should stop because the order is closed.
+// If cancelled is true the order cancelled by a previous attempt is re-created instead of being treated as closed.
+func (p *PlotOrderer) update(ctx context.Context, po *PlotOrder, order ClientOrder, price float64, cancelled bool) error {
+	current, err := p.client.GetOrder(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	po.setOrder(current)
+
+	recreate := cancelled && current.OrderStatus() == OrderStatusCanceled
+	if current.OrderStatus().Closed() && !recreate {
+		return errOrderClosed
+	}
+
+	if !recreate && p.unchanged(ctx, po, current, price) {
+		return errUpdateSkipped
+	}
+
+	newOrder, err := p.client.UpdateOrderPrice(ctx, current, price)
+	if err != nil {
+		refreshed, getErr := p.client.GetOrder(ctx, current)
+		if getErr != nil {
 			return err
 		}
 
-		po.Order = newOrder
+		po.setOrder(refreshed)
+
+		// the order could have been filled or expired right before the update, in that case it's not a failure,
+		// a cancelled order was cancelled by the update itself, clients cancel the order before they create the new one
+		switch refreshed.OrderStatus() {
+		case OrderStatusCanceled:
+			return fmt.Errorf("%w: %w", errNotReplaced, err)
+		case OrderStatusFilled, OrderStatusExpired:
+			return errOrderClosed
+		}
 
 		return err
 	}
+
+	po.setOrder(newOrder)
+	if newOrder.OrderStatus().Closed() {
+		return errOrderClosed
+	}
+
+	return nil
 }
 
 func (p *PlotOrderer) cancelOrder(ctx context.Context, plotOrderID string, cancelOrder bool) error {
@@ -129,9 +473,10 @@ func (p *PlotOrderer) cancelOrder(ctx context.Context, plotOrderID string, cance
 	}
 
 	po.Stop()
+	p.scheduler.Unschedule(po.ID)
 	//delete(p.plotOrders, po.ID)
-	if cancelOrder {
-		return p.client.CancelOrder(ctx, po.Order)
+	if cancelOrder && po.order() != nil {
+		return p.client.CancelOrder(ctx, po.order())
 	}
 
 	return nil
@@ -0,0 +1,425 @@
+package plotor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOrder struct {
+	ID     int
+	Symbol string
+	Price  float64
+	Status plotor.OrderStatus
+}
+
+func (o *fakeOrder) Details() (map[string]any, error) {
+	return map[string]any{"id": o.ID, "price": o.Price, "status": o.Status}, nil
+}
+
+func (o *fakeOrder) OrderStatus() plotor.OrderStatus {
+	return o.Status
+}
+
+func (o *fakeOrder) ExchangeOrderID() string {
+	return strconv.Itoa(o.ID)
+}
+
+func (o *fakeOrder) OrderSymbol() string {
+	return o.Symbol
+}
+
+func (o *fakeOrder) OrderPrice() float64 {
+	return o.Price
+}
+
+// fakeClient keeps orders in memory, every price update cancels the order and replaces it with a new one
+type fakeClient struct {
+	mu     sync.Mutex
+	nextID int
+	orders map[int]*fakeOrder
+	prices []float64
+	// updateErr is returned from UpdateOrderPrice when set, like the real clients the old order is cancelled before
+	// the new one fails to be created
+	updateErr error
+	// failures are returned one by one from UpdateOrderPrice before updateErr
+	failures []error
+	// marketFailures are returned one by one from MarketOrder after it cancelled the order
+	marketFailures []error
+	// updateGate blocks UpdateOrderPrice until it receives a value when set
+	updateGate chan struct{}
+	// marketPrices are the market prices of symbols
+	marketPrices map[string]float64
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{orders: map[int]*fakeOrder{}, marketPrices: map[string]float64{}}
+}
+
+// CreateOrder accepts the symbol as order data
+func (c *fakeClient) CreateOrder(_ context.Context, orderData any, price float64) (plotor.ClientOrder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	symbol, _ := orderData.(string)
+	return c.create(symbol, price), nil
+}
+
+func (c *fakeClient) GetOrder(_ context.Context, order plotor.ClientOrder) (plotor.ClientOrder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, ok := c.orders[order.(*fakeOrder).ID]
+	if !ok {
+		return nil, fmt.Errorf("order %d not found", order.(*fakeOrder).ID)
+	}
+
+	cp := *o
+	return &cp, nil
+}
+
+func (c *fakeClient) UpdateOrderPrice(_ context.Context, order plotor.ClientOrder, price float64) (plotor.ClientOrder, error) {
+	if c.updateGate != nil {
+		<-c.updateGate
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// a cancelled order is only re-created
+	c.orders[order.(*fakeOrder).ID].Status = plotor.OrderStatusCanceled
+
+	if len(c.failures) > 0 {
+		err := c.failures[0]
+		c.failures = c.failures[1:]
+		return nil, err
+	}
+
+	if c.updateErr != nil {
+		return nil, c.updateErr
+	}
+
+	return c.create(order.(*fakeOrder).Symbol, price), nil
+}
+
+// CancelOrder fails for closed orders like the exchanges do
+func (c *fakeClient) CancelOrder(_ context.Context, order plotor.ClientOrder) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o := c.orders[order.(*fakeOrder).ID]
+	if o.Status.Closed() {
+		return fmt.Errorf("order %d is %s", o.ID, o.Status)
+	}
+
+	o.Status = plotor.OrderStatusCanceled
+	return nil
+}
+
+// MarketOrder replaces the order with one that is filled right away
+func (c *fakeClient) MarketOrder(_ context.Context, order plotor.ClientOrder) (plotor.ClientOrder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.orders[order.(*fakeOrder).ID].Status = plotor.OrderStatusCanceled
+
+	if len(c.marketFailures) > 0 {
+		err := c.marketFailures[0]
+		c.marketFailures = c.marketFailures[1:]
+		return nil, err
+	}
+
+	o := c.create(order.(*fakeOrder).Symbol, 0)
+	c.orders[o.ID].Status = plotor.OrderStatusFilled
+	o.Status = plotor.OrderStatusFilled
+
+	return o, nil
+}
+
+func (c *fakeClient) MarketPrice(_ context.Context, symbol string) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	price, ok := c.marketPrices[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no market price of %s", symbol)
+	}
+
+	return price, nil
+}
+
+func (c *fakeClient) setMarketPrice(symbol string, price float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.marketPrices[symbol] = price
+}
+
+func (c *fakeClient) create(symbol string, price float64) *fakeOrder {
+	c.nextID++
+	o := &fakeOrder{ID: c.nextID, Symbol: symbol, Price: price, Status: plotor.OrderStatusNew}
+	c.orders[o.ID] = o
+	c.prices = append(c.prices, price)
+	cp := *o
+	return &cp
+}
+
+// setStatus changes the status of the most recent order
+func (c *fakeClient) setStatus(status plotor.OrderStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.orders[c.nextID].Status = status
+}
+
+// setOrderStatus changes the status of the order with given ID
+func (c *fakeClient) setOrderStatus(id int, status plotor.OrderStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.orders[id].Status = status
+}
+
+func (c *fakeClient) priceCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.prices)
+}
+
+func TestPlotOrderer_StopsWhenOrderCloses(t *testing.T) {
+	tests := []struct {
+		orderStatus plotor.OrderStatus
+		want        plotor.Status
+	}{
+		{plotor.OrderStatusFilled, plotor.StatusFilled},
+		{plotor.OrderStatusCanceled, plotor.StatusCancelled},
+		{plotor.OrderStatusExpired, plotor.StatusExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.orderStatus), func(t *testing.T) {
+			client := newFakeClient()
+			orderer := plotor.NewPlotOrderer(client)
+
+			updates := statusChanges(orderer)
+
+			po, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Second)
+			assert.NoError(t, err)
+			assert.Equal(t, plotor.StatusPending, po.Snapshot().Status)
+
+			client.setStatus(tt.orderStatus)
+
+			assert.Equal(t, plotor.StatusRunning, waitStatus(t, updates))
+			assert.Equal(t, tt.want, waitStatus(t, updates))
+
+			// the closed order must not be replaced
+			assert.Equal(t, 1, client.priceCount())
+		})
+	}
+}
+
+func TestPlotOrderer_CreateFilledRightAway(t *testing.T) {
+	client := newFakeClient()
+	orderer := plotor.NewPlotOrderer(client)
+
+	po := plotor.NewPlotOrder(mustCreate(t, client, plotor.OrderStatusFilled), &geometry.Line{B: 10}, time.Hour)
+
+	po, err := orderer.Restore(context.Background(), po)
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.StatusFilled, po.Snapshot().Status)
+}
+
+func TestPlotOrderer_UpdateErrorFails(t *testing.T) {
+	client := newFakeClient()
+	client.updateErr = errors.New("exchange down")
+	orderer := plotor.NewPlotOrderer(client)
+
+	updates := statusChanges(orderer)
+	events, unsubscribe := orderer.Subscribe(10)
+	defer unsubscribe()
+
+	po, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Second)
+	assert.NoError(t, err)
+
+	// the old order is cancelled by the update, the plot order fails instead of being cancelled
+	assert.Equal(t, plotor.StatusRunning, waitStatus(t, updates))
+	assert.Equal(t, plotor.StatusFailed, waitStatus(t, updates))
+	assert.ErrorIs(t, po.Snapshot().Err, client.updateErr)
+	assert.Equal(t, plotor.OrderStatusCanceled, po.Snapshot().Order.OrderStatus())
+
+	assert.Equal(t, []plotor.EventType{plotor.EventCreated, plotor.EventError}, eventTypes(t, events, 2))
+}
+
+func TestPlotOrderer_PauseResume(t *testing.T) {
+	client := newFakeClient()
+	orderer := plotor.NewPlotOrderer(client)
+
+	updates := statusChanges(orderer)
+
+	po, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Second)
+	assert.NoError(t, err)
+
+	assert.NoError(t, orderer.Pause(po.ID))
+	assert.Equal(t, plotor.StatusPaused, waitStatus(t, updates))
+	assert.Error(t, orderer.Pause(po.ID))
+
+	// a paused plot order must not update the order
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, 1, client.priceCount())
+
+	assert.NoError(t, orderer.Resume(context.Background(), po.ID))
+	assert.Equal(t, plotor.StatusPending, waitStatus(t, updates))
+	assert.Error(t, orderer.Resume(context.Background(), po.ID))
+
+	assert.Equal(t, plotor.StatusRunning, waitStatus(t, updates))
+	assert.Eventually(t, func() bool { return client.priceCount() == 2 }, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, orderer.Stop(context.Background(), po.ID, false))
+	assert.NoError(t, orderer.Stop(context.Background(), po.ID, false))
+	assert.Error(t, orderer.Resume(context.Background(), po.ID))
+	assert.Equal(t, plotor.StatusStopped, po.Snapshot().Status)
+}
+
+func TestStatus_CanTransition(t *testing.T) {
+	tests := []struct {
+		from, to plotor.Status
+		want     bool
+	}{
+		{plotor.StatusPending, plotor.StatusRunning, true},
+		{plotor.StatusRunning, plotor.StatusPaused, true},
+		{plotor.StatusPaused, plotor.StatusPending, true},
+		{plotor.StatusPaused, plotor.StatusRunning, false},
+		{plotor.StatusRunning, plotor.StatusPending, false},
+		{plotor.StatusRunning, plotor.StatusFilled, true},
+		{plotor.StatusStopped, plotor.StatusPending, false},
+		{plotor.StatusFilled, plotor.StatusStopped, false},
+		{plotor.StatusFailed, plotor.StatusRunning, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransition(tt.to))
+		})
+	}
+}
+
+// statusChanges returns a channel receiving the status of a plot order every time it changes
+func statusChanges(orderer *plotor.PlotOrderer) chan plotor.Status {
+	changes := make(chan plotor.Status, 10)
+	last := plotor.StatusPending
+	mu := &sync.Mutex{}
+
+	orderer.OnUpdate(func(po *plotor.PlotOrder) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if po.Status != last {
+			last = po.Status
+			changes <- po.Status
+		}
+	})
+
+	return changes
+}
+
+func waitStatus(t *testing.T, changes chan plotor.Status) plotor.Status {
+	t.Helper()
+
+	select {
+	case status := <-changes:
+		return status
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for plot order status change")
+		return ""
+	}
+}
+
+func mustCreate(t *testing.T, client *fakeClient, status plotor.OrderStatus) plotor.ClientOrder {
+	order, err := client.CreateOrder(context.Background(), nil, 10)
+	assert.NoError(t, err)
+	client.setStatus(status)
+	return order
+}
+
+func TestPlotOrderer_Update(t *testing.T) {
+	client := newFakeClient()
+	orderer := plotor.NewPlotOrderer(client)
+	ctx := context.Background()
+
+	var persisted *plotor.PlotOrder
+	orderer.OnUpdate(func(po *plotor.PlotOrder) { persisted = po })
+
+	po, err := orderer.Create(ctx, nil, &geometry.Line{B: 10}, time.Hour)
+	assert.NoError(t, err)
+
+	_, err = orderer.Update(ctx, po.ID, nil, 0)
+	assert.Error(t, err)
+
+	_, err = orderer.Update(ctx, po.ID, &neverValid{}, 0)
+	assert.Error(t, err)
+	assert.Equal(t, &geometry.Line{B: 10}, po.Snapshot().Plot)
+
+	updated, err := orderer.Update(ctx, po.ID, &geometry.Line{B: 20}, 2*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, &geometry.Line{B: 20}, updated.Plot)
+	assert.Equal(t, 2*time.Hour, updated.Interval)
+	assert.Equal(t, []float64{10, 20}, client.prices)
+
+	// the order is replaced, not created from scratch
+	assert.Equal(t, 2, updated.Order.(*fakeOrder).ID)
+
+	// paused plot orders are not re-priced but the new plot is persisted
+	assert.NoError(t, orderer.Pause(po.ID))
+	_, err = orderer.Update(ctx, po.ID, &geometry.Line{B: 30}, 3*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 20}, client.prices)
+	assert.Equal(t, &geometry.Line{B: 30}, persisted.Plot)
+	assert.Equal(t, 3*time.Hour, persisted.Interval)
+
+	assert.NoError(t, orderer.Stop(ctx, po.ID, false))
+	_, err = orderer.Update(ctx, po.ID, &geometry.Line{B: 40}, 0)
+	assert.Error(t, err)
+}
+
+type neverValid struct{}
+
+func (neverValid) At(time.Time) (float64, error) {
+	return 0, geometry.ErrOutOfRange
+}
+
+func TestPlotOrderer_List(t *testing.T) {
+	client := newFakeClient()
+	orderer := plotor.NewPlotOrderer(client)
+	ctx := context.Background()
+
+	btc, err := orderer.Create(ctx, "BTCUSDT", &geometry.Line{B: 10}, time.Hour)
+	assert.NoError(t, err)
+
+	eth, err := orderer.Create(ctx, "ETHUSDT", &geometry.Line{B: 10}, time.Hour)
+	assert.NoError(t, err)
+
+	paused, err := orderer.Create(ctx, "BTCUSDT", &geometry.Line{B: 10}, time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, orderer.Pause(paused.ID))
+
+	ids := func(pos []*plotor.PlotOrder) []string {
+		ids := []string{}
+		for _, po := range pos {
+			ids = append(ids, po.ID)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{btc.ID, eth.ID, paused.ID}, ids(orderer.List(plotor.ListFilter{})))
+	assert.ElementsMatch(t, []string{btc.ID, paused.ID}, ids(orderer.List(plotor.ListFilter{Symbol: "BTCUSDT"})))
+	assert.ElementsMatch(t, []string{paused.ID}, ids(orderer.List(plotor.ListFilter{Statuses: []plotor.Status{plotor.StatusPaused}})))
+	assert.ElementsMatch(t, []string{btc.ID}, ids(orderer.List(plotor.ListFilter{Symbol: "BTCUSDT", Statuses: []plotor.Status{plotor.StatusPending}})))
+	assert.Empty(t, orderer.List(plotor.ListFilter{Symbol: "BNBUSDT"}))
+
+	assert.NoError(t, orderer.StopAll(ctx, false))
+}
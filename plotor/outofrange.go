@@ -0,0 +1,146 @@
+package plotor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// OutOfRangePolicy decides what happens to the order when the plot has no price at a tick, see geometry.ErrOutOfRange
+type OutOfRangePolicy string
+
+const (
+	// OutOfRangeLeave stops the plot order and leaves the order at the last price
+	OutOfRangeLeave OutOfRangePolicy = "LEAVE"
+	// OutOfRangeCancel stops the plot order and cancels the order
+	OutOfRangeCancel OutOfRangePolicy = "CANCEL"
+	// OutOfRangeMarket stops the plot order and replaces the order with a market order, the client has to implement MarketClient
+	OutOfRangeMarket OutOfRangePolicy = "MARKET"
+	// OutOfRangeWait leaves the order at the last price and keeps ticking until the plot is back in range
+	OutOfRangeWait OutOfRangePolicy = "WAIT"
+)
+
+func (p OutOfRangePolicy) Validate() error {
+	switch p {
+	case OutOfRangeLeave, OutOfRangeCancel, OutOfRangeMarket, OutOfRangeWait:
+		return nil
+	}
+
+	return fmt.Errorf("unsupported out of range policy: %s", p)
+}
+
+// MarketClient is implemented by clients that can replace an order with a market order
+type MarketClient interface {
+	// MarketOrder cancels the order and creates a market order for its remaining quantity,
+	// a cancelled order is only replaced so a market order that failed after the cancel can be repeated
+	MarketOrder(ctx context.Context, order ClientOrder) (newOrder ClientOrder, err error)
+}
+
+// WithOutOfRange sets the out of range policy of the plot order
+func WithOutOfRange(policy OutOfRangePolicy) Option {
+	return func(po *PlotOrder) {
+		po.OutOfRange = policy
+	}
+}
+
+// outOfRange handles a tick at which the plot has no price according to the out of range policy,
+// it returns false when the plot order should stop running
+func (po *PlotOrder) outOfRange(err error) (bool, error) {
+	po.mu.Lock()
+	policy := po.OutOfRange
+	exit := po.exit
+	waiting := po.waiting
+	po.waiting = policy == OutOfRangeWait
+	po.mu.Unlock()
+
+	if !waiting {
+		po.publish(EventOutOfRange)
+	}
+
+	switch policy {
+	case OutOfRangeWait:
+		return true, nil
+	case OutOfRangeCancel, OutOfRangeMarket:
+		if exit == nil {
+			err = fmt.Errorf("out of range policy %s requires a plot orderer: %w", policy, err)
+			po.finish(StatusFailed, err)
+			return false, err
+		}
+
+		order, exitErr := exit(policy)
+		if exitErr != nil {
+			exitErr = fmt.Errorf("error applying out of range policy %s: %w", policy, exitErr)
+			po.finish(StatusFailed, exitErr)
+			return false, exitErr
+		}
+
+		po.setOrder(order)
+
+		if status := order.OrderStatus(); status.Closed() {
+			po.finish(statusFromOrder(status), nil)
+			return false, nil
+		}
+	}
+
+	po.finish(StatusStopped, err)
+
+	return false, nil
+}
+
+// exit returns a function that cancels the order or replaces it with a market order when the plot goes out of range
+func (p *PlotOrderer) exit(ctx context.Context, po *PlotOrder) func(policy OutOfRangePolicy) (ClientOrder, error) {
+	return func(policy OutOfRangePolicy) (ClientOrder, error) {
+		var newOrder ClientOrder
+
+		// cancelled is set when an attempt cancelled the order but failed to create the market order
+		cancelled := false
+
+		err := po.retry(func() error {
+			order, err := p.client.GetOrder(ctx, po.order())
+			if err != nil {
+				return err
+			}
+
+			// there is nothing to cancel or replace if the order got closed in the meantime,
+			// unless it was cancelled by a previous attempt in which case the market order is created again
+			recreate := cancelled && order.OrderStatus() == OrderStatusCanceled
+			if order.OrderStatus().Closed() && !recreate {
+				newOrder = order
+				return nil
+			}
+
+			switch policy {
+			case OutOfRangeCancel:
+				if err := p.client.CancelOrder(ctx, order); err != nil {
+					return err
+				}
+
+				newOrder, err = p.client.GetOrder(ctx, order)
+
+				return err
+			case OutOfRangeMarket:
+				mc, ok := p.client.(MarketClient)
+				if !ok {
+					return errors.New("client does not support market orders")
+				}
+
+				newOrder, err = mc.MarketOrder(ctx, order)
+				if err != nil {
+					// clients cancel the order before they create the market order
+					if refreshed, getErr := p.client.GetOrder(ctx, order); getErr == nil && refreshed.OrderStatus() == OrderStatusCanceled {
+						po.setOrder(refreshed)
+						cancelled = true
+
+						return fmt.Errorf("%w: %w", errNotReplaced, err)
+					}
+				}
+
+				return err
+			}
+
+			return fmt.Errorf("unsupported out of range policy: %s", policy)
+		})
+
+		return newOrder, err
+	}
+}
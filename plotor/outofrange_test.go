@@ -0,0 +1,159 @@
+package plotor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+// gap is a plot with price equal to the unix time that is out of range in [From, To)
+type gap struct {
+	From, To time.Time
+}
+
+func (g gap) At(t time.Time) (float64, error) {
+	if !t.Before(g.From) && t.Before(g.To) {
+		return 0, geometry.ErrOutOfRange
+	}
+
+	return float64(t.Unix()), nil
+}
+
+func TestPlotOrderer_OutOfRange(t *testing.T) {
+	transient := fmt.Errorf("%w: exchange busy", plotor.ErrTransient)
+	minute := func(m int) time.Time { return time.Date(2023, 1, 15, 0, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		policy      plotor.OutOfRangePolicy
+		want        plotor.Status
+		orderStatus plotor.OrderStatus
+	}{
+		{plotor.OutOfRangeLeave, plotor.StatusStopped, plotor.OrderStatusNew},
+		{plotor.OutOfRangeCancel, plotor.StatusCancelled, plotor.OrderStatusCanceled},
+		{plotor.OutOfRangeMarket, plotor.StatusFilled, plotor.OrderStatusFilled},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			clock := plotor.NewManualClock(minute(0).Add(30 * time.Second))
+			scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+			defer scheduler.Stop()
+
+			client := newFakeClient()
+			orderer := plotor.NewPlotOrderer(client)
+			orderer.SetScheduler(scheduler)
+
+			updates := statusChanges(orderer)
+
+			po, err := orderer.Create(context.Background(), nil, gap{From: minute(1), To: minute(2)}, time.Minute, plotor.WithOutOfRange(tt.policy))
+			assert.NoError(t, err)
+
+			clock.BlockUntil(1)
+			clock.Set(minute(1))
+
+			assert.Equal(t, plotor.StatusRunning, waitStatus(t, updates))
+			assert.Equal(t, tt.want, waitStatus(t, updates))
+
+			snapshot := po.Snapshot()
+			assert.Equal(t, tt.orderStatus, snapshot.Order.OrderStatus())
+
+			if tt.policy == plotor.OutOfRangeLeave {
+				assert.ErrorIs(t, snapshot.Err, geometry.ErrOutOfRange)
+			} else {
+				assert.NoError(t, snapshot.Err)
+			}
+
+			// the plot order is not updated anymore
+			clock.BlockUntil(0)
+			assert.Equal(t, 0, scheduler.Stats().Scheduled)
+		})
+	}
+
+	t.Run(string(plotor.OutOfRangeWait), func(t *testing.T) {
+		clock := plotor.NewManualClock(minute(0).Add(30 * time.Second))
+		scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+		defer scheduler.Stop()
+
+		client := newFakeClient()
+		orderer := plotor.NewPlotOrderer(client)
+		orderer.SetScheduler(scheduler)
+
+		po, err := orderer.Create(context.Background(), nil, gap{From: minute(1), To: minute(2)}, time.Minute, plotor.WithOutOfRange(plotor.OutOfRangeWait))
+		assert.NoError(t, err)
+
+		clock.BlockUntil(1)
+		clock.Set(minute(1))
+		clock.BlockUntil(1)
+
+		// the order is left at the last price
+		assert.Equal(t, 1, client.priceCount())
+		assert.Equal(t, plotor.StatusRunning, po.Snapshot().Status)
+		assert.True(t, po.Snapshot().LastTick.IsZero())
+
+		clock.Set(minute(2))
+		clock.BlockUntil(1)
+
+		assert.Equal(t, 2, client.priceCount())
+		assert.Equal(t, minute(2), po.Snapshot().LastTick)
+	})
+
+	t.Run("MARKET after failure", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			failures []error
+			want     plotor.Status
+		}{
+			{"recovers", []error{transient}, plotor.StatusFilled},
+			{"permanent error", []error{plotor.ErrPermanent}, plotor.StatusFailed},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				clock := plotor.NewManualClock(minute(0).Add(30 * time.Second))
+				scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+				defer scheduler.Stop()
+
+				// the market order fails after the resting order was cancelled
+				client := newFakeClient()
+				client.marketFailures = tt.failures
+				orderer := plotor.NewPlotOrderer(client)
+				orderer.SetScheduler(scheduler)
+
+				updates := statusChanges(orderer)
+
+				po, err := orderer.Create(context.Background(), nil, gap{From: minute(1), To: minute(2)}, time.Minute,
+					plotor.WithOutOfRange(plotor.OutOfRangeMarket), plotor.WithRetry(plotor.RetryPolicy{MaxAttempts: 2}))
+				assert.NoError(t, err)
+
+				clock.BlockUntil(1)
+				clock.Set(minute(1))
+
+				assert.Equal(t, plotor.StatusRunning, waitStatus(t, updates))
+				assert.Equal(t, tt.want, waitStatus(t, updates))
+
+				snapshot := po.Snapshot()
+				if tt.want == plotor.StatusFilled {
+					assert.Equal(t, plotor.OrderStatusFilled, snapshot.Order.OrderStatus())
+					assert.NoError(t, snapshot.Err)
+					return
+				}
+
+				// the plot order must not be reported as cancelled
+				assert.Equal(t, plotor.OrderStatusCanceled, snapshot.Order.OrderStatus())
+				assert.ErrorIs(t, snapshot.Err, plotor.ErrPermanent)
+			})
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		orderer := plotor.NewPlotOrderer(newFakeClient())
+
+		_, err := orderer.Create(context.Background(), nil, &geometry.Line{B: 10}, time.Minute, plotor.WithOutOfRange("IGNORE"))
+		assert.Error(t, err)
+	})
+}
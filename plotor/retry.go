@@ -0,0 +1,95 @@
+package plotor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RetryPolicy controls how many times a failed update of a plot order is repeated before the plot order fails,
+// only transient errors (see IsTransient) are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, values below 2 disable retries
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts, zero means no cap
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after every attempt, values below 1 keep it constant
+	Multiplier float64
+}
+
+// DefaultRetryPolicy is used by plot orders that were not given a policy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+}
+
+func (rp RetryPolicy) Validate() error {
+	if rp.MaxAttempts < 0 {
+		return fmt.Errorf("max attempts can not be negative, got %d", rp.MaxAttempts)
+	}
+
+	if rp.InitialBackoff < 0 || rp.MaxBackoff < 0 {
+		return errors.New("backoff can not be negative")
+	}
+
+	return nil
+}
+
+// Backoff returns the wait after given failed attempt, attempts are counted from 1
+func (rp RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := math.Max(rp.Multiplier, 1)
+	backoff := float64(rp.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+
+	if rp.MaxBackoff > 0 && backoff > float64(rp.MaxBackoff) {
+		return rp.MaxBackoff
+	}
+
+	return time.Duration(backoff)
+}
+
+// Option configures a plot order when it's created
+type Option func(po *PlotOrder)
+
+// WithRetry sets the retry policy of the plot order
+func WithRetry(policy RetryPolicy) Option {
+	return func(po *PlotOrder) {
+		po.Retry = policy
+	}
+}
+
+// retry calls f until it succeeds, returns an error that is not transient or runs out of attempts,
+// it gives up early when the plot order is stopped while waiting for the next attempt
+func (po *PlotOrder) retry(f func() error) error {
+	po.mu.Lock()
+	policy := po.Retry
+	po.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || errors.Is(err, errOrderClosed) || !IsTransient(err) {
+			return err
+		}
+
+		if attempt >= policy.MaxAttempts {
+			if attempt > 1 {
+				return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			}
+
+			return err
+		}
+
+		timer := po.clock.NewTimer(policy.Backoff(attempt))
+
+		select {
+		case <-timer.C():
+		case <-po.stopChan():
+			timer.Stop()
+			return err
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package plotor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := plotor.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4))
+
+	constant := plotor.RetryPolicy{InitialBackoff: time.Second}
+	assert.Equal(t, time.Second, constant.Backoff(10))
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("unknown"), false},
+		{fmt.Errorf("wrapped: %w", plotor.ErrTransient), true},
+		{fmt.Errorf("wrapped: %w", plotor.ErrInternalClientError), true},
+		{fmt.Errorf("wrapped: %w", plotor.ErrPermanent), false},
+		{fmt.Errorf("wrapped: %w", plotor.ErrInternalError), false},
+		{fmt.Errorf("%w: %w", plotor.ErrPermanent, plotor.ErrTransient), false},
+		{context.DeadlineExceeded, true},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, plotor.IsTransient(tt.err), "%v", tt.err)
+	}
+}
+
+func TestPlotOrderer_Retry(t *testing.T) {
+	transient := fmt.Errorf("%w: exchange busy", plotor.ErrTransient)
+	policy := plotor.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, Multiplier: 2}
+
+	tests := []struct {
+		name string
+		// failures are returned by the client after it cancelled the order, the retries have to re-create it
+		failures []error
+		want     plotor.Status
+		// waits are the backoffs the plot order waits for
+		waits []time.Duration
+	}{
+		{"recovers", []error{transient, transient}, plotor.StatusRunning, []time.Duration{time.Second, 2 * time.Second}},
+		{"runs out of attempts", []error{transient, transient, transient}, plotor.StatusFailed, []time.Duration{time.Second, 2 * time.Second}},
+		{"permanent error", []error{transient, plotor.ErrPermanent}, plotor.StatusFailed, []time.Duration{time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2023, 1, 15, 0, 0, 30, 0, time.UTC)
+			clock := plotor.NewManualClock(start)
+			scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+			defer scheduler.Stop()
+
+			client := newFakeClient()
+			client.failures = tt.failures
+			orderer := plotor.NewPlotOrderer(client)
+			orderer.SetScheduler(scheduler)
+
+			updates := statusChanges(orderer)
+
+			po, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Minute, plotor.WithRetry(policy))
+			assert.NoError(t, err)
+
+			clock.BlockUntil(1)
+			clock.Advance(30 * time.Second)
+			assert.Equal(t, plotor.StatusRunning, waitStatus(t, updates))
+
+			for _, wait := range tt.waits {
+				// the backoff timer is the only one while the update is in progress
+				clock.BlockUntil(1)
+				clock.Advance(wait)
+			}
+
+			if tt.want == plotor.StatusRunning {
+				assert.Eventually(t, func() bool { return client.priceCount() == 2 }, time.Second, time.Millisecond)
+				assert.Equal(t, start.Add(30*time.Second), po.Snapshot().LastTick)
+				assert.Equal(t, plotor.OrderStatusNew, po.Snapshot().Order.OrderStatus())
+				return
+			}
+
+			assert.Equal(t, tt.want, waitStatus(t, updates))
+			assert.Equal(t, 1, client.priceCount())
+			assert.ErrorIs(t, po.Snapshot().Err, tt.failures[len(tt.failures)-1])
+			assert.ErrorContains(t, po.Snapshot().Err, "cancelled but not replaced")
+		})
+	}
+}
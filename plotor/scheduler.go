@@ -0,0 +1,392 @@
+package plotor
+
+import (
+	"container/heap"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/H3Cki/Plotor/logger"
+)
+
+const DEFAULT_WORKERS = 16
+
+// SchedulerConfig controls how the updates of plot orders are dispatched
+type SchedulerConfig struct {
+	// Workers is the maximum number of plot orders updated at once, DEFAULT_WORKERS is used when it's not positive
+	Workers int
+	// Spread distributes the updates of plot orders sharing an interval boundary evenly over this duration
+	Spread time.Duration
+	// Jitter is the maximum random delay added to every update
+	Jitter time.Duration
+}
+
+// SchedulerStats describes the state of the scheduler at a moment
+type SchedulerStats struct {
+	// Scheduled is the number of plot orders waiting for their interval boundary
+	Scheduled int
+	// Delayed is the number of updates waiting for their spread or jitter delay
+	Delayed int
+	// Queued is the number of updates waiting for a free worker
+	Queued int
+	// Running is the number of updates in progress
+	Running int
+	Workers int
+}
+
+// Scheduler updates plot orders at their interval boundaries using a bounded pool of workers,
+// plot orders with the same boundary are put in one bucket and dispatched together
+type Scheduler struct {
+	clock   Clock
+	cfg     SchedulerConfig
+	buckets map[time.Time][]*entry
+	// boundaries holds the boundaries of buckets, it may contain boundaries of buckets that were emptied
+	boundaries timeHeap
+	delayed    entryHeap
+	ready      []*entry
+	// entries holds the current entry of every scheduled plot order, entries that are not in this map are skipped
+	entries map[string]*entry
+	running int
+	stopped bool
+	wakeC   chan struct{}
+	// cond is signalled when an entry becomes ready or the scheduler stops
+	cond *sync.Cond
+	mu   *sync.Mutex
+}
+
+type entry struct {
+	po       *PlotOrder
+	handler  Handler
+	boundary time.Time
+	due      time.Time
+}
+
+var (
+	defaultScheduler   *Scheduler
+	defaultSchedulerMu = &sync.Mutex{}
+)
+
+// DefaultScheduler returns the scheduler shared by plot orderers, it's created with the real clock on the first use
+func DefaultScheduler() *Scheduler {
+	defaultSchedulerMu.Lock()
+	defer defaultSchedulerMu.Unlock()
+
+	if defaultScheduler == nil {
+		defaultScheduler = NewScheduler(RealClock{}, SchedulerConfig{})
+	}
+
+	return defaultScheduler
+}
+
+// SetDefaultScheduler replaces the shared scheduler, it has to be called before plot orderers are created
+func SetDefaultScheduler(s *Scheduler) {
+	defaultSchedulerMu.Lock()
+	defer defaultSchedulerMu.Unlock()
+	defaultScheduler = s
+}
+
+// NewScheduler is a constructor for Scheduler, it starts the scheduler right away
+func NewScheduler(clock Clock, cfg SchedulerConfig) *Scheduler {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DEFAULT_WORKERS
+	}
+
+	mu := &sync.Mutex{}
+	s := &Scheduler{
+		clock:   clock,
+		cfg:     cfg,
+		buckets: map[time.Time][]*entry{},
+		entries: map[string]*entry{},
+		wakeC:   make(chan struct{}, 1),
+		cond:    sync.NewCond(mu),
+		mu:      mu,
+	}
+
+	go s.loop()
+
+	for i := 0; i < cfg.Workers; i++ {
+		go s.work()
+	}
+
+	return s
+}
+
+func (s *Scheduler) Clock() Clock {
+	return s.clock
+}
+
+// Schedule updates the plot order at the start of the next interval and then every interval until the handler stops it,
+// scheduling a plot order that is already scheduled replaces the previous schedule
+func (s *Scheduler) Schedule(po *PlotOrder, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(po.ID)
+	s.add(&entry{po: po, handler: handler, boundary: NextIntervalStart(s.clock.Now(), po.interval())})
+	s.wake()
+}
+
+// Unschedule stops updating the plot order, an update that is already running is not interrupted
+func (s *Scheduler) Unschedule(plotOrderID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(plotOrderID)
+	s.wake()
+}
+
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SchedulerStats{Running: s.running, Workers: s.cfg.Workers}
+
+	for _, bucket := range s.buckets {
+		stats.Scheduled += len(bucket)
+	}
+
+	for _, e := range s.delayed {
+		if s.current(e) {
+			stats.Delayed++
+		}
+	}
+
+	for _, e := range s.ready {
+		if s.current(e) {
+			stats.Queued++
+		}
+	}
+
+	return stats
+}
+
+// Stop stops the scheduler, updates that are already running are finished
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+
+	s.stopped = true
+	s.cond.Broadcast()
+	close(s.wakeC)
+}
+
+// loop waits for the nearest boundary or delayed entry and releases the entries that are due
+func (s *Scheduler) loop() {
+	for {
+		s.mu.Lock()
+		if s.stopped {
+			s.mu.Unlock()
+			return
+		}
+
+		now := s.clock.Now()
+		s.release(now)
+		next, ok := s.next()
+		s.mu.Unlock()
+
+		var (
+			timer  Timer
+			timerC <-chan time.Time
+		)
+
+		if ok {
+			timer = s.clock.NewTimer(next.Sub(now))
+			timerC = timer.C()
+		}
+
+		select {
+		case <-s.wakeC:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-timerC:
+		}
+	}
+}
+
+// release moves buckets that reached their boundary to delayed entries and delayed entries that are due to ready ones
+func (s *Scheduler) release(now time.Time) {
+	for len(s.boundaries) > 0 && !s.boundaries[0].After(now) {
+		boundary := heap.Pop(&s.boundaries).(time.Time)
+		bucket := s.buckets[boundary]
+		delete(s.buckets, boundary)
+
+		for i, e := range bucket {
+			e.due = boundary.Add(s.delay(i, len(bucket)))
+			heap.Push(&s.delayed, e)
+		}
+	}
+
+	for len(s.delayed) > 0 && !s.delayed[0].due.After(now) {
+		e := heap.Pop(&s.delayed).(*entry)
+		if s.current(e) {
+			s.ready = append(s.ready, e)
+			s.cond.Signal()
+		}
+	}
+}
+
+// next returns the time of the nearest boundary or delayed entry
+func (s *Scheduler) next() (time.Time, bool) {
+	for len(s.boundaries) > 0 {
+		if _, ok := s.buckets[s.boundaries[0]]; ok {
+			break
+		}
+
+		heap.Pop(&s.boundaries)
+	}
+
+	var (
+		next time.Time
+		ok   bool
+	)
+
+	if len(s.boundaries) > 0 {
+		next, ok = s.boundaries[0], true
+	}
+
+	if len(s.delayed) > 0 && (!ok || s.delayed[0].due.Before(next)) {
+		next, ok = s.delayed[0].due, true
+	}
+
+	return next, ok
+}
+
+// delay returns the delay of i-th out of n entries sharing a boundary
+func (s *Scheduler) delay(i, n int) time.Duration {
+	d := s.cfg.Spread * time.Duration(i) / time.Duration(n)
+
+	if s.cfg.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(s.cfg.Jitter)))
+	}
+
+	return d
+}
+
+// work updates ready plot orders and schedules them again for the next interval
+func (s *Scheduler) work() {
+	for {
+		s.mu.Lock()
+		for len(s.ready) == 0 && !s.stopped {
+			s.cond.Wait()
+		}
+
+		if s.stopped {
+			s.mu.Unlock()
+			return
+		}
+
+		e := s.ready[0]
+		s.ready = s.ready[1:]
+
+		if !s.current(e) {
+			s.mu.Unlock()
+			continue
+		}
+
+		s.running++
+		s.mu.Unlock()
+
+		again, err := e.po.step(e.boundary, e.handler)
+		if err != nil {
+			logger.Errorf("error updating plot order: %v", err)
+		}
+
+		s.mu.Lock()
+		s.running--
+
+		// the plot order could have been unscheduled or rescheduled during the update
+		if s.current(e) {
+			delete(s.entries, e.po.ID)
+
+			if again {
+				s.add(&entry{po: e.po, handler: e.handler, boundary: NextIntervalStart(s.clock.Now(), e.po.interval())})
+				s.wake()
+			}
+		}
+
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) add(e *entry) {
+	if _, ok := s.buckets[e.boundary]; !ok {
+		heap.Push(&s.boundaries, e.boundary)
+	}
+
+	s.buckets[e.boundary] = append(s.buckets[e.boundary], e)
+	s.entries[e.po.ID] = e
+}
+
+// remove forgets the entry of the plot order, it's removed from its bucket right away and skipped later if it was already released
+func (s *Scheduler) remove(plotOrderID string) {
+	e, ok := s.entries[plotOrderID]
+	if !ok {
+		return
+	}
+
+	delete(s.entries, plotOrderID)
+
+	bucket := s.buckets[e.boundary]
+	for i, be := range bucket {
+		if be == e {
+			bucket = append(bucket[:i], bucket[i+1:]...)
+			break
+		}
+	}
+
+	if len(bucket) == 0 {
+		delete(s.buckets, e.boundary)
+		return
+	}
+
+	s.buckets[e.boundary] = bucket
+}
+
+func (s *Scheduler) current(e *entry) bool {
+	return s.entries[e.po.ID] == e
+}
+
+// wake makes the loop recalculate the nearest event
+func (s *Scheduler) wake() {
+	if s.stopped {
+		return
+	}
+
+	select {
+	case s.wakeC <- struct{}{}:
+	default:
+	}
+}
+
+type timeHeap []time.Time
+
+func (h timeHeap) Len() int           { return len(h) }
+func (h timeHeap) Less(i, j int) bool { return h[i].Before(h[j]) }
+func (h timeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *timeHeap) Push(x any)        { *h = append(*h, x.(time.Time)) }
+
+func (h *timeHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+type entryHeap []*entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].due.Before(h[j].due) }
+func (h entryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *entryHeap) Push(x any)        { *h = append(*h, x.(*entry)) }
+
+func (h *entryHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,106 @@
+package plotor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+// newScheduledOrderer returns an orderer with n plot orders on a 1m interval scheduled by its own scheduler with a manual clock
+func newScheduledOrderer(t *testing.T, client *fakeClient, cfg plotor.SchedulerConfig, n int) (*plotor.PlotOrderer, *plotor.Scheduler, *plotor.ManualClock) {
+	t.Helper()
+
+	clock := plotor.NewManualClock(time.Date(2023, 1, 15, 0, 0, 30, 0, time.UTC))
+	scheduler := plotor.NewScheduler(clock, cfg)
+	t.Cleanup(scheduler.Stop)
+
+	orderer := plotor.NewPlotOrderer(client)
+	orderer.SetScheduler(scheduler)
+
+	for i := 0; i < n; i++ {
+		// the price is the unix time so the orders are re-priced at every tick
+		_, err := orderer.Create(context.Background(), nil, &geometry.Line{A: 1}, time.Minute)
+		assert.NoError(t, err)
+	}
+
+	// all plot orders share one boundary so there is a single timer
+	clock.BlockUntil(1)
+	assert.Equal(t, plotor.SchedulerStats{Scheduled: n, Workers: scheduler.Stats().Workers}, scheduler.Stats())
+
+	return orderer, scheduler, clock
+}
+
+func TestScheduler_Workers(t *testing.T) {
+	client := newFakeClient()
+	client.updateGate = make(chan struct{})
+
+	_, scheduler, clock := newScheduledOrderer(t, client, plotor.SchedulerConfig{Workers: 2}, 5)
+
+	clock.Advance(30 * time.Second)
+
+	// only two updates run at once, the rest is queued
+	assert.Eventually(t, func() bool {
+		return scheduler.Stats() == plotor.SchedulerStats{Queued: 3, Running: 2, Workers: 2}
+	}, time.Second, time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		client.updateGate <- struct{}{}
+	}
+
+	assert.Eventually(t, func() bool {
+		return scheduler.Stats() == plotor.SchedulerStats{Scheduled: 5, Workers: 2}
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 10, client.priceCount())
+}
+
+func TestScheduler_Spread(t *testing.T) {
+	client := newFakeClient()
+
+	_, scheduler, clock := newScheduledOrderer(t, client, plotor.SchedulerConfig{Spread: 4 * time.Second}, 4)
+
+	clock.Advance(30 * time.Second)
+
+	// the updates are dispatched one per second
+	for i := 1; i <= 4; i++ {
+		if i > 1 {
+			clock.Advance(time.Second)
+		}
+
+		assert.Eventually(t, func() bool { return client.priceCount() == 4+i }, time.Second, time.Millisecond)
+		assert.Equal(t, 4-i, scheduler.Stats().Delayed)
+	}
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	client := newFakeClient()
+
+	_, scheduler, clock := newScheduledOrderer(t, client, plotor.SchedulerConfig{Jitter: 10 * time.Second}, 10)
+
+	clock.Advance(30 * time.Second)
+	clock.Advance(10 * time.Second)
+
+	assert.Eventually(t, func() bool { return client.priceCount() == 20 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return scheduler.Stats().Scheduled == 10 }, time.Second, time.Millisecond)
+}
+
+func TestScheduler_Unschedule(t *testing.T) {
+	client := newFakeClient()
+
+	orderer, scheduler, clock := newScheduledOrderer(t, client, plotor.SchedulerConfig{}, 3)
+	pos := orderer.List(plotor.ListFilter{})
+
+	assert.NoError(t, orderer.Pause(pos[0].ID))
+	assert.NoError(t, orderer.Stop(context.Background(), pos[1].ID, false))
+	assert.Equal(t, 1, scheduler.Stats().Scheduled)
+
+	clock.Advance(30 * time.Second)
+	assert.Eventually(t, func() bool { return client.priceCount() == 4 }, time.Second, time.Millisecond)
+
+	// a resumed plot order joins the bucket of the next boundary
+	assert.NoError(t, orderer.Resume(context.Background(), pos[0].ID))
+	assert.Eventually(t, func() bool { return scheduler.Stats().Scheduled == 2 }, time.Second, time.Millisecond)
+}
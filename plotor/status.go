@@ -0,0 +1,89 @@
+package plotor
+
+// OrderStatus is a client independent status of an order on the exchange
+type OrderStatus string
+
+const (
+	OrderStatusNew             OrderStatus = "NEW"
+	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusFilled          OrderStatus = "FILLED"
+	OrderStatusCanceled        OrderStatus = "CANCELED"
+	OrderStatusExpired         OrderStatus = "EXPIRED"
+	OrderStatusRejected        OrderStatus = "REJECTED"
+	OrderStatusUnknown         OrderStatus = "UNKNOWN"
+)
+
+// Closed returns true if the order can no longer be filled
+func (s OrderStatus) Closed() bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusExpired, OrderStatusRejected:
+		return true
+	}
+
+	return false
+}
+
+// Status is the status of a plot order
+type Status string
+
+const (
+	// StatusPending means that the plot order is waiting for the start of the next interval to start running
+	StatusPending Status = "PENDING"
+	// StatusRunning means that the order is being updated every interval
+	StatusRunning Status = "RUNNING"
+	// StatusPaused means that the order is not being updated until the plot order is resumed
+	StatusPaused Status = "PAUSED"
+	// StatusStopped means that the plot order was stopped by the user
+	StatusStopped Status = "STOPPED"
+	// StatusFilled means that the order was filled on the exchange
+	StatusFilled Status = "FILLED"
+	// StatusCancelled means that the order was cancelled or rejected on the exchange
+	StatusCancelled Status = "CANCELLED"
+	// StatusExpired means that the order expired on the exchange
+	StatusExpired Status = "EXPIRED"
+	// StatusFailed means that the plot order stopped because of an error
+	StatusFailed Status = "FAILED"
+)
+
+// transitions lists statuses that a plot order can move to from a given status,
+// terminal statuses have no transitions
+var transitions = map[Status][]Status{
+	StatusPending: {StatusRunning, StatusPaused, StatusStopped, StatusFilled, StatusCancelled, StatusExpired, StatusFailed},
+	StatusRunning: {StatusPaused, StatusStopped, StatusFilled, StatusCancelled, StatusExpired, StatusFailed},
+	StatusPaused:  {StatusPending, StatusStopped, StatusFilled, StatusCancelled, StatusExpired, StatusFailed},
+}
+
+// CanTransition returns true if a plot order with status s can move to status to
+func (s Status) CanTransition(to Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Active returns true if the plot order is waiting for or running updates
+func (s Status) Active() bool {
+	return s == StatusPending || s == StatusRunning
+}
+
+// Terminal returns true if the plot order will not be updated anymore
+func (s Status) Terminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// statusFromOrder returns the plot order status corresponding to a closed order status
+func statusFromOrder(s OrderStatus) Status {
+	switch s {
+	case OrderStatusFilled:
+		return StatusFilled
+	case OrderStatusExpired:
+		return StatusExpired
+	case OrderStatusCanceled, OrderStatusRejected:
+		return StatusCancelled
+	}
+
+	return StatusFailed
+}
@@ -0,0 +1,209 @@
+package plotor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+)
+
+// Trigger delays placing the order of a plot order until the market price crosses the trigger plot in given direction,
+// the plot order stays pending until then. The market price has to cross the plot, the order is not placed when
+// the market price is already on the Direction side when the plot order is created.
+type Trigger struct {
+	// Symbol is the symbol of the market price, the client has to implement PriceClient
+	Symbol string
+	Plot   geometry.Plot
+	// Direction is the side of the trigger plot the market price has to move to
+	Direction AlertSide
+	// Side is the side of the trigger plot the market price was on at the last check
+	Side AlertSide
+	// MarketPrice is the market price at the last check
+	MarketPrice float64
+	// Triggered is the time at which the order was placed, it's zero while the plot order waits for the crossing
+	Triggered time.Time
+	// OrderData is passed to Client.CreateOrder when the plot order is triggered
+	OrderData any
+}
+
+func (t Trigger) Validate() error {
+	if t.Symbol == "" {
+		return errors.New("trigger symbol can not be empty")
+	}
+
+	if t.Plot == nil {
+		return errors.New("trigger plot can not be empty")
+	}
+
+	if t.Direction != AlertSideAbove && t.Direction != AlertSideBelow {
+		return fmt.Errorf("unsupported trigger direction: %s", t.Direction)
+	}
+
+	return nil
+}
+
+func (t *Trigger) copy() *Trigger {
+	if t == nil {
+		return nil
+	}
+
+	cp := *t
+	return &cp
+}
+
+// WithTrigger makes the plot order wait for the market price of the symbol to cross the trigger plot in given direction
+// before the order is placed, see Trigger
+func WithTrigger(symbol string, plot geometry.Plot, direction AlertSide) Option {
+	return func(po *PlotOrder) {
+		po.Trigger = &Trigger{Symbol: symbol, Plot: plot, Direction: direction}
+	}
+}
+
+// armed returns true if the plot order waits for its trigger
+func (po *PlotOrder) armed() bool {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return po.Trigger != nil && po.Trigger.Triggered.IsZero()
+}
+
+func (po *PlotOrder) trigger() *Trigger {
+	po.mu.Lock()
+	defer po.mu.Unlock()
+	return po.Trigger.copy()
+}
+
+// check checks the trigger at time t, it returns false when the plot order should not be scheduled again
+func (po *PlotOrder) check(t time.Time) (bool, error) {
+	po.tickMu.Lock()
+	defer po.tickMu.Unlock()
+
+	po.mu.Lock()
+	activate := po.activate
+	po.mu.Unlock()
+
+	if activate == nil {
+		err := errors.New("trigger requires a plot orderer")
+		po.finish(StatusFailed, err)
+		return false, err
+	}
+
+	return activate(t)
+}
+
+// createTriggered registers a plot order that waits for its trigger, the order is placed by activate
+func (p *PlotOrderer) createTriggered(ctx context.Context, po *PlotOrder, orderData any) (*PlotOrder, error) {
+	pc, ok := p.client.(PriceClient)
+	if !ok {
+		return nil, errors.New("client does not support market prices")
+	}
+
+	if err := po.Trigger.Validate(); err != nil {
+		return nil, err
+	}
+
+	triggerPrice, err := po.Trigger.Plot.At(p.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error getting price from the trigger plot: %w", err)
+	}
+
+	marketPrice, err := pc.MarketPrice(ctx, po.Trigger.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("error getting market price: %w", err)
+	}
+
+	po.Trigger.Side, _ = crossSide("", marketPrice, triggerPrice)
+	po.Trigger.MarketPrice = marketPrice
+	po.Trigger.OrderData = orderData
+
+	p.register(ctx, po)
+	po.publish(EventCreated)
+	p.run(ctx, po)
+
+	return po, nil
+}
+
+// activate returns a function that compares the market price to the trigger plot and places the order
+// once the market price crosses the trigger plot in the direction of the trigger
+func (p *PlotOrderer) activate(ctx context.Context, po *PlotOrder) func(t time.Time) (bool, error) {
+	return func(t time.Time) (bool, error) {
+		trigger := po.trigger()
+
+		triggerPrice, err := trigger.Plot.At(t)
+		if errors.Is(err, geometry.ErrOutOfRange) {
+			// the trigger can not be crossed outside of its range
+			return true, nil
+		}
+
+		if err != nil {
+			po.finish(StatusFailed, err)
+			return false, err
+		}
+
+		pc, ok := p.client.(PriceClient)
+		if !ok {
+			err := errors.New("client does not support market prices")
+			po.finish(StatusFailed, err)
+			return false, err
+		}
+
+		var marketPrice float64
+
+		err = po.retry(func() error {
+			price, err := pc.MarketPrice(ctx, trigger.Symbol)
+			marketPrice = price
+			return err
+		})
+		if err != nil {
+			po.finish(StatusFailed, err)
+			return false, err
+		}
+
+		side, crossed := crossSide(trigger.Side, marketPrice, triggerPrice)
+
+		po.mu.Lock()
+		po.Trigger.Side = side
+		po.Trigger.MarketPrice = marketPrice
+		po.mu.Unlock()
+
+		if !crossed || side != trigger.Direction {
+			po.update()
+			return true, nil
+		}
+
+		price, err := po.plot().At(t)
+		if err != nil {
+			err = fmt.Errorf("error getting price from the plot: %w", err)
+			po.finish(StatusFailed, err)
+			return false, err
+		}
+
+		order, err := p.client.CreateOrder(ctx, trigger.OrderData, price)
+		if err != nil {
+			err = fmt.Errorf("error creating order: %w", err)
+			po.finish(StatusFailed, err)
+			return false, err
+		}
+
+		po.mu.Lock()
+		po.Order = order
+		po.Trigger.Triggered = t
+		po.LastTick = t
+		po.mu.Unlock()
+
+		po.publish(EventTriggered)
+
+		if status := order.OrderStatus(); status.Closed() {
+			po.finish(statusFromOrder(status), nil)
+			return false, nil
+		}
+
+		if err := po.transition(StatusRunning, nil); err != nil {
+			// the plot order was paused or stopped in the meantime
+			return false, nil
+		}
+
+		return true, nil
+	}
+}
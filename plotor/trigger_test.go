@@ -0,0 +1,91 @@
+package plotor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlotOrderer_Trigger(t *testing.T) {
+	start := time.Date(2023, 1, 15, 0, 0, 30, 0, time.UTC)
+	clock := plotor.NewManualClock(start)
+	scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+	defer scheduler.Stop()
+
+	client := newFakeClient()
+	client.setMarketPrice("BTCUSDT", 110)
+	orderer := plotor.NewPlotOrderer(client)
+	orderer.SetScheduler(scheduler)
+
+	events, unsubscribe := orderer.Subscribe(0)
+	defer unsubscribe()
+
+	// the market price starts above the trigger so it has to go below and come back
+	po, err := orderer.Create(context.Background(), "BTCUSDT", &geometry.Line{A: 1}, time.Minute,
+		plotor.WithTrigger("BTCUSDT", &geometry.Line{B: 100}, plotor.AlertSideAbove))
+	assert.NoError(t, err)
+	assert.Equal(t, plotor.EventCreated, (<-events).Type)
+	assert.Empty(t, client.prices)
+
+	tick := func(marketPrice float64) {
+		client.setMarketPrice("BTCUSDT", marketPrice)
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		clock.BlockUntil(1)
+	}
+
+	tick(120)
+	tick(90)
+
+	snapshot := po.Snapshot()
+	assert.Equal(t, plotor.StatusPending, snapshot.Status)
+	assert.Nil(t, snapshot.Order)
+	assert.Equal(t, plotor.AlertSideBelow, snapshot.Trigger.Side)
+	assert.Len(t, orderer.List(plotor.ListFilter{Symbol: "BTCUSDT"}), 1)
+
+	// a plot order waiting for its trigger is not re-priced when its plot is replaced
+	_, err = orderer.Update(context.Background(), po.ID, &geometry.Line{A: 1, B: 1}, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, client.prices)
+
+	tick(101)
+
+	e := <-events
+	assert.Equal(t, plotor.EventTriggered, e.Type)
+	assert.Equal(t, 101.0, e.MarketPrice)
+
+	triggered := start.Add(150 * time.Second).Truncate(time.Minute)
+	snapshot = po.Snapshot()
+	assert.Equal(t, plotor.StatusRunning, snapshot.Status)
+	assert.Equal(t, triggered, snapshot.Trigger.Triggered)
+	assert.Equal(t, []float64{float64(triggered.Unix()) + 1}, client.prices)
+
+	// once triggered the order follows the plot regardless of the market price
+	tick(50)
+	assert.Equal(t, []float64{float64(triggered.Unix()) + 1, float64(triggered.Add(time.Minute).Unix()) + 1}, client.prices)
+	assert.Equal(t, plotor.EventRepriced, (<-events).Type)
+}
+
+func TestPlotOrderer_TriggerInvalid(t *testing.T) {
+	client := newFakeClient()
+	client.setMarketPrice("BTCUSDT", 110)
+	orderer := plotor.NewPlotOrderer(client)
+
+	_, err := orderer.Create(context.Background(), nil, &geometry.Line{B: 10}, time.Minute, plotor.WithTrigger("", &geometry.Line{B: 100}, plotor.AlertSideAbove))
+	assert.Error(t, err)
+
+	_, err = orderer.Create(context.Background(), nil, &geometry.Line{B: 10}, time.Minute, plotor.WithTrigger("BTCUSDT", &geometry.Line{B: 100}, "SIDEWAYS"))
+	assert.Error(t, err)
+
+	_, err = orderer.Create(context.Background(), nil, &geometry.Line{B: 10}, time.Minute, plotor.WithTrigger("BTCUSDT", neverValid{}, plotor.AlertSideBelow))
+	assert.Error(t, err)
+
+	_, err = orderer.Create(context.Background(), nil, &geometry.Line{B: 10}, time.Minute, plotor.WithTrigger("ETHUSDT", &geometry.Line{B: 100}, plotor.AlertSideBelow))
+	assert.Error(t, err)
+
+	assert.Empty(t, client.prices)
+}
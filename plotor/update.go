@@ -0,0 +1,76 @@
+package plotor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// errUpdateSkipped is returned by a handler when the order already rests close enough to the new price
+var errUpdateSkipped = errors.New("update skipped")
+
+// UpdatePolicy decides when re-pricing the order is not worth it, the order is never re-priced to the price it already has
+type UpdatePolicy struct {
+	// MinChange is the minimum absolute difference between the current and the new price
+	MinChange float64
+	// MinChangePct is the minimum difference relative to the current price, e.g. 0.001 for 0.1%
+	MinChangePct float64
+}
+
+func (up UpdatePolicy) Validate() error {
+	if up.MinChange < 0 || up.MinChangePct < 0 {
+		return fmt.Errorf("minimum price change can not be negative")
+	}
+
+	return nil
+}
+
+// skip returns true if the order resting at current price does not need to be moved to the new price
+func (up UpdatePolicy) skip(current, price float64) bool {
+	// the current price is unknown
+	if current <= 0 {
+		return false
+	}
+
+	diff := math.Abs(price - current)
+
+	// prices parsed from exchange responses may differ from filtered prices by a rounding error
+	if diff <= current*1e-12 || diff < up.MinChange {
+		return true
+	}
+
+	return up.MinChangePct > 0 && diff/current < up.MinChangePct
+}
+
+// PriceFilter is implemented by clients that round prices before placing orders, e.g. to the tick size of the symbol
+type PriceFilter interface {
+	FilterPrice(ctx context.Context, symbol string, price float64) (float64, error)
+}
+
+// WithUpdatePolicy sets the update policy of the plot order
+func WithUpdatePolicy(policy UpdatePolicy) Option {
+	return func(po *PlotOrder) {
+		po.UpdatePolicy = policy
+	}
+}
+
+// unchanged returns true if the order does not need to be re-priced according to the update policy of the plot order,
+// the price is compared after it's filtered by the client
+func (p *PlotOrderer) unchanged(ctx context.Context, po *PlotOrder, order ClientOrder, price float64) bool {
+	if pf, ok := p.client.(PriceFilter); ok {
+		filtered, err := pf.FilterPrice(ctx, order.OrderSymbol(), price)
+		if err != nil {
+			// the client reports the problem when the order is updated
+			return false
+		}
+
+		price = filtered
+	}
+
+	po.mu.Lock()
+	policy := po.UpdatePolicy
+	po.mu.Unlock()
+
+	return policy.skip(order.OrderPrice(), price)
+}
@@ -0,0 +1,65 @@
+package plotor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/geometry"
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlotOrderer_UpdatePolicy(t *testing.T) {
+	start := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	// the price starts at 100 and rises by 1 every minute
+	rising := &geometry.Line{A: 1.0 / 60, B: 100 - float64(start.Unix())/60}
+
+	tests := []struct {
+		name   string
+		plot   geometry.Plot
+		policy plotor.UpdatePolicy
+		// prices are the prices of orders after 4 ticks
+		prices  []float64
+		skipped int
+	}{
+		{"same price", &geometry.Line{B: 100}, plotor.UpdatePolicy{}, []float64{100}, 4},
+		{"no threshold", rising, plotor.UpdatePolicy{}, []float64{100, 101, 102, 103, 104}, 0},
+		{"absolute", rising, plotor.UpdatePolicy{MinChange: 2}, []float64{100, 102, 104}, 2},
+		{"percentage", rising, plotor.UpdatePolicy{MinChangePct: 0.025}, []float64{100, 103}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := plotor.NewManualClock(start)
+			scheduler := plotor.NewScheduler(clock, plotor.SchedulerConfig{Workers: 1})
+			defer scheduler.Stop()
+
+			client := newFakeClient()
+			orderer := plotor.NewPlotOrderer(client)
+			orderer.SetScheduler(scheduler)
+
+			po, err := orderer.Create(context.Background(), nil, tt.plot, time.Minute, plotor.WithUpdatePolicy(tt.policy))
+			assert.NoError(t, err)
+
+			for m := 1; m <= 4; m++ {
+				clock.BlockUntil(1)
+				clock.Advance(time.Minute)
+			}
+
+			clock.BlockUntil(1)
+
+			for i := range tt.prices {
+				assert.InDelta(t, tt.prices[i], client.prices[i], 1e-9)
+			}
+
+			assert.Len(t, client.prices, len(tt.prices))
+			assert.Equal(t, tt.skipped, po.Snapshot().SkippedTicks)
+			assert.Equal(t, start.Add(4*time.Minute), po.Snapshot().LastTick)
+		})
+	}
+
+	_, err := plotor.NewPlotOrderer(newFakeClient()).Create(context.Background(), nil, &geometry.Line{B: 10}, time.Minute, plotor.WithUpdatePolicy(plotor.UpdatePolicy{MinChange: -1}))
+	assert.Error(t, err)
+}
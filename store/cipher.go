@@ -0,0 +1,62 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KeySize is the size of the key a Cipher is created with
+const KeySize = 32
+
+// Cipher encrypts secrets before they are persisted, e.g. the credentials of sessions, using AES-256-GCM
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher is a constructor for Cipher, the key has to be KeySize bytes long
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes long, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// Seal encrypts the plaintext, the result starts with a random nonce
+func (c *Cipher) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Open decrypts data returned by Seal, it fails if the data was sealed with a different key or was modified
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(data) < size {
+		return nil, errors.New("error decrypting: data too short")
+	}
+
+	plaintext, err := c.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting: %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,36 @@
+package store_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/H3Cki/Plotor/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCipher(t *testing.T) {
+	_, err := store.NewCipher([]byte("short"))
+	assert.Error(t, err)
+
+	c, err := store.NewCipher(bytes.Repeat([]byte{1}, store.KeySize))
+	assert.NoError(t, err)
+
+	secret := []byte(`{"API_KEY":"a","SECRET_KEY":"b"}`)
+
+	sealed, err := c.Seal(secret)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(sealed), "SECRET_KEY")
+
+	opened, err := c.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, opened)
+
+	other, err := store.NewCipher(bytes.Repeat([]byte{2}, store.KeySize))
+	assert.NoError(t, err)
+
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+
+	_, err = c.Open(sealed[:4])
+	assert.Error(t, err)
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	sessionsDir   = "sessions"
+	plotOrdersDir = "plotorders"
+	bracketsDir   = "brackets"
+)
+
+// FileStore is a Store that keeps every record as a separate JSON file inside of a directory
+type FileStore struct {
+	dir string
+	mu  *sync.Mutex
+}
+
+// NewFileStore is a constructor for FileStore, it creates the directory structure if it does not exist
+func NewFileStore(dir string) (*FileStore, error) {
+	for _, sub := range []string{sessionsDir, plotOrdersDir, bracketsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, fmt.Errorf("error creating store directory: %w", err)
+		}
+	}
+
+	return &FileStore{
+		dir: dir,
+		mu:  &sync.Mutex{},
+	}, nil
+}
+
+func (fs *FileStore) SaveSession(s Session) error {
+	return fs.save(sessionsDir, s.Token, s)
+}
+
+func (fs *FileStore) DeleteSession(token string) error {
+	return fs.delete(sessionsDir, token)
+}
+
+func (fs *FileStore) Sessions() ([]Session, error) {
+	sessions := []Session{}
+
+	err := fs.list(sessionsDir, func(data []byte) error {
+		s := Session{}
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+
+		sessions = append(sessions, s)
+
+		return nil
+	})
+
+	return sessions, err
+}
+
+func (fs *FileStore) SavePlotOrder(po PlotOrder) error {
+	return fs.save(plotOrdersDir, po.ID, po)
+}
+
+func (fs *FileStore) PlotOrder(id string) (PlotOrder, error) {
+	po := PlotOrder{}
+
+	data, err := fs.read(plotOrdersDir, id)
+	if err != nil {
+		return po, err
+	}
+
+	if err := json.Unmarshal(data, &po); err != nil {
+		return po, fmt.Errorf("error unmarshalling plot order %s: %w", id, err)
+	}
+
+	return po, nil
+}
+
+func (fs *FileStore) DeletePlotOrder(id string) error {
+	return fs.delete(plotOrdersDir, id)
+}
+
+func (fs *FileStore) PlotOrders() ([]PlotOrder, error) {
+	plotOrders := []PlotOrder{}
+
+	err := fs.list(plotOrdersDir, func(data []byte) error {
+		po := PlotOrder{}
+		if err := json.Unmarshal(data, &po); err != nil {
+			return err
+		}
+
+		plotOrders = append(plotOrders, po)
+
+		return nil
+	})
+
+	return plotOrders, err
+}
+
+func (fs *FileStore) SaveBracket(b Bracket) error {
+	return fs.save(bracketsDir, b.ID, b)
+}
+
+func (fs *FileStore) Bracket(id string) (Bracket, error) {
+	b := Bracket{}
+
+	data, err := fs.read(bracketsDir, id)
+	if err != nil {
+		return b, err
+	}
+
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("error unmarshalling bracket %s: %w", id, err)
+	}
+
+	return b, nil
+}
+
+func (fs *FileStore) DeleteBracket(id string) error {
+	return fs.delete(bracketsDir, id)
+}
+
+func (fs *FileStore) Brackets() ([]Bracket, error) {
+	brackets := []Bracket{}
+
+	err := fs.list(bracketsDir, func(data []byte) error {
+		b := Bracket{}
+		if err := json.Unmarshal(data, &b); err != nil {
+			return err
+		}
+
+		brackets = append(brackets, b)
+
+		return nil
+	})
+
+	return brackets, err
+}
+
+// save writes the record to a temporary file first and then renames it, so that a crash never leaves a partially written record
+func (fs *FileStore) save(sub, key string, record any) error {
+	path, err := fs.path(sub, key)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error marshalling record: %w", err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("error writing record: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error writing record: %w", err)
+	}
+
+	return nil
+}
+
+func (fs *FileStore) read(sub, key string) ([]byte, error) {
+	path, err := fs.path(sub, key)
+	if err != nil {
+		return nil, err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return data, err
+}
+
+func (fs *FileStore) delete(sub, key string) error {
+	path, err := fs.path(sub, key)
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (fs *FileStore) list(sub string, decode func(data []byte) error) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join(fs.dir, sub))
+	if err != nil {
+		return fmt.Errorf("error listing records: %w", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		names = append(names, entry.Name())
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(fs.dir, sub, name))
+		if err != nil {
+			return fmt.Errorf("error reading record %s: %w", name, err)
+		}
+
+		if err := decode(data); err != nil {
+			return fmt.Errorf("error unmarshalling record %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (fs *FileStore) path(sub, key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid record key: %q", key)
+	}
+
+	return filepath.Join(fs.dir, sub, key+".json"), nil
+}
@@ -0,0 +1,113 @@
+package store_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore_Sessions(t *testing.T) {
+	fs, err := store.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	s := store.Session{Token: "token", Hash: []byte{1, 2, 3}, Client: "BINANCE_SPOT", Auth: []byte{4, 5, 6}}
+	assert.NoError(t, fs.SaveSession(s))
+
+	sessions, err := fs.Sessions()
+	assert.NoError(t, err)
+	assert.Equal(t, []store.Session{s}, sessions)
+
+	assert.NoError(t, fs.DeleteSession(s.Token))
+	assert.ErrorIs(t, fs.DeleteSession(s.Token), store.ErrNotFound)
+
+	sessions, err = fs.Sessions()
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestFileStore_PlotOrders(t *testing.T) {
+	dir := t.TempDir()
+
+	fs, err := store.NewFileStore(dir)
+	assert.NoError(t, err)
+
+	po := store.PlotOrder{
+		ID:           "id",
+		SessionToken: "token",
+		Client:       "BINANCE_SPOT",
+		Plot:         json.RawMessage(`{"Type":"line","Args":{}}`),
+		Interval:     "1h0m0s",
+		Status:       "PAUSED",
+		Order:        json.RawMessage(`{"orderId":1}`),
+		LastTick:     time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC),
+		History:      json.RawMessage(`[{"Time":"2023-01-15T12:00:00Z","PlotPrice":10}]`),
+	}
+	assert.NoError(t, fs.SavePlotOrder(po))
+
+	po.LastTick = po.LastTick.Add(time.Hour)
+	assert.NoError(t, fs.SavePlotOrder(po))
+
+	// records must survive reopening the store
+	fs, err = store.NewFileStore(dir)
+	assert.NoError(t, err)
+
+	got, err := fs.PlotOrder(po.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, po, got)
+
+	all, err := fs.PlotOrders()
+	assert.NoError(t, err)
+	assert.Equal(t, []store.PlotOrder{po}, all)
+
+	assert.NoError(t, fs.DeletePlotOrder(po.ID))
+
+	_, err = fs.PlotOrder(po.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestFileStore_Brackets(t *testing.T) {
+	dir := t.TempDir()
+
+	fs, err := store.NewFileStore(dir)
+	assert.NoError(t, err)
+
+	b := store.Bracket{
+		ID:           "id",
+		SessionToken: "token",
+		Status:       "ENTRY",
+		EntryID:      "entry",
+		TakeProfitID: "tp",
+		StopLossID:   "sl",
+		Interval:     "1m0s",
+		TakeProfit:   json.RawMessage(`{"Plot":{"Type":"line","Args":{}}}`),
+		StopLoss:     json.RawMessage(`{"Plot":{"Type":"line","Args":{}}}`),
+	}
+	assert.NoError(t, fs.SaveBracket(b))
+
+	// records must survive reopening the store
+	fs, err = store.NewFileStore(dir)
+	assert.NoError(t, err)
+
+	got, err := fs.Bracket(b.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, b, got)
+
+	all, err := fs.Brackets()
+	assert.NoError(t, err)
+	assert.Equal(t, []store.Bracket{b}, all)
+
+	assert.NoError(t, fs.DeleteBracket(b.ID))
+	assert.ErrorIs(t, fs.DeleteBracket(b.ID), store.ErrNotFound)
+}
+
+func TestFileStore_InvalidKey(t *testing.T) {
+	fs, err := store.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "../escape", "a/b", `a\b`} {
+		assert.Error(t, fs.SavePlotOrder(store.PlotOrder{ID: key}))
+	}
+}
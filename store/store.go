@@ -0,0 +1,75 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// Session holds everything that is required to recreate a session and its client after a restart
+type Session struct {
+	Token  string
+	Hash   []byte
+	Client string
+	// Auth holds the credentials of the client encrypted with the Cipher of the server, they are never stored in plaintext
+	Auth []byte
+	// Webhooks holds the webhooks registered in the session, they are encrypted like Auth because they contain their secrets
+	Webhooks []byte `json:",omitempty"`
+}
+
+// PlotOrder holds everything that is required to resume a plot order after a restart
+type PlotOrder struct {
+	ID           string
+	SessionToken string
+	Client       string
+	Plot         json.RawMessage
+	Interval     string
+	Status       string
+	Order        json.RawMessage
+	// Alert is set instead of Order when the plot order is an alert
+	Alert json.RawMessage `json:",omitempty"`
+	// Trigger is set when the plot order waits or waited for its trigger, Order is empty until it's triggered
+	Trigger  json.RawMessage `json:",omitempty"`
+	LastTick time.Time
+	// Options holds optional settings the plot order was created with
+	Options json.RawMessage `json:",omitempty"`
+	// History holds the records of the most recent ticks
+	History json.RawMessage `json:",omitempty"`
+}
+
+// Bracket holds everything that is required to link the plot orders of a bracket again after a restart,
+// the plot orders of its legs are persisted as separate PlotOrder records
+type Bracket struct {
+	ID           string
+	SessionToken string
+	Status       string
+	Error        string `json:",omitempty"`
+	EntryID      string
+	TakeProfitID string
+	StopLossID   string
+	Interval     string
+	// EntryPartiallyFilled is set once the order of the entry becomes partially filled
+	EntryPartiallyFilled bool `json:",omitempty"`
+	// TakeProfit and StopLoss hold the legs used to place the exits once the entry is filled
+	TakeProfit json.RawMessage
+	StopLoss   json.RawMessage
+}
+
+// Store persists sessions, plot orders and brackets
+type Store interface {
+	SaveSession(s Session) error
+	DeleteSession(token string) error
+	Sessions() ([]Session, error)
+
+	SavePlotOrder(po PlotOrder) error
+	PlotOrder(id string) (PlotOrder, error)
+	DeletePlotOrder(id string) error
+	PlotOrders() ([]PlotOrder, error)
+
+	SaveBracket(b Bracket) error
+	Bracket(id string) (Bracket, error)
+	DeleteBracket(id string) error
+	Brackets() ([]Bracket, error)
+}
@@ -0,0 +1,456 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/google/uuid"
+)
+
+// Headers sent with every delivery
+const (
+	// SIGNATURE_HEADER holds "sha256=" followed by the hex encoded HMAC-SHA256 of the body keyed with the secret of the webhook
+	SIGNATURE_HEADER = "X-Plotor-Signature"
+	EVENT_HEADER     = "X-Plotor-Event"
+	DELIVERY_HEADER  = "X-Plotor-Delivery"
+)
+
+// DEFAULT_LOG_SIZE is the number of deliveries kept in the delivery log
+const DEFAULT_LOG_SIZE = 100
+
+// DefaultRetryPolicy is used by dispatchers that were not given a policy
+var DefaultRetryPolicy = plotor.RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+	Multiplier:     2,
+}
+
+// Webhook is a registration of an URL notified about events of plot orders
+type Webhook struct {
+	ID  string
+	URL string
+	// Secret signs the payloads, see SIGNATURE_HEADER
+	Secret string
+	// Events lists the event types the webhook is notified about, it's notified about all events when it's empty
+	Events []plotor.EventType
+}
+
+// ErrPrivateHost is returned for webhooks pointing to loopback, private or other non-public addresses,
+// deliveries to them could reach services of the server's network that are not exposed to the internet
+var ErrPrivateHost = errors.New("webhook host must be public")
+
+// Validate checks the webhook, hosts that are non-public IP addresses or localhost are rejected with ErrPrivateHost.
+// Hostnames resolving to non-public addresses can only be caught when connecting, see NewHTTPClient.
+func (w Webhook) Validate() error {
+	return w.validate(false)
+}
+
+func (w Webhook) validate(allowPrivate bool) error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("error parsing url: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) url, got %q", w.URL)
+	}
+
+	if !allowPrivate && !publicHost(u.Hostname()) {
+		return fmt.Errorf("%w, got %q", ErrPrivateHost, u.Hostname())
+	}
+
+	if w.Secret == "" {
+		return errors.New("secret can not be empty")
+	}
+
+	return nil
+}
+
+// publicHost returns false for localhost and IP addresses that are not public, other hostnames are not resolved
+func publicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return publicIP(ip)
+	}
+
+	return true
+}
+
+// reservedPrefixes are the address ranges webhooks can not be delivered to, they are private, local, shared
+// or special-purpose according to the IANA special-purpose address registries
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // this network
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space (CGNAT)
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, includes cloud metadata endpoints
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation (TEST-NET-1)
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation (TEST-NET-2)
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation (TEST-NET-3)
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, includes broadcast
+	netip.MustParsePrefix("::/96"),           // unspecified, loopback and IPv4-compatible
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use IPv4/IPv6 translation
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, includes Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, embeds any IPv4 address
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("fec0::/10"),       // site-local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// nat64Prefix is the well-known NAT64 prefix, the IPv4 address in its last 4 bytes is checked instead
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// publicIP returns false for addresses in reservedPrefixes, IPv4-mapped and NAT64 addresses are checked by their IPv4 address
+func publicIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	if nat64Prefix.Contains(addr) {
+		b := addr.As16()
+		addr = netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]})
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewHTTPClient returns a client for NewDispatcher which refuses to connect to non-public addresses,
+// unlike Validate it also catches hostnames and redirects that lead to such addresses
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w, got %q", ErrPrivateHost, host)
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// a proxy would be dialed instead of the host of the webhook
+	transport.Proxy = nil
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func (w Webhook) match(e plotor.Event) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, typ := range w.Events {
+		if typ == e.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Delivery is an entry of the delivery log describing the outcome of sending an event to a webhook
+type Delivery struct {
+	ID        string
+	WebhookID string
+	Event     plotor.Event
+	Time      time.Time
+	Attempts  int
+	// StatusCode is the status code of the last response, zero if there was none
+	StatusCode int
+	Delivered  bool
+	Error      string `json:",omitempty"`
+}
+
+// Sign returns the value of SIGNATURE_HEADER for the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the value of SIGNATURE_HEADER, it can be used by receivers
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Dispatcher sends events to registered webhooks, failed deliveries are retried with backoff in the background
+type Dispatcher struct {
+	client   *http.Client
+	retry    plotor.RetryPolicy
+	webhooks map[string]Webhook
+	log      []Delivery
+	logSize  int
+	// allowPrivate makes Add accept webhooks with non-public hosts
+	allowPrivate bool
+	// stopC is closed when the dispatcher is closed, it interrupts waiting for the next attempt
+	stopC chan struct{}
+	wg    *sync.WaitGroup
+	mu    *sync.Mutex
+}
+
+// NewDispatcher is a constructor for Dispatcher, http.DefaultClient is used when the client is nil
+func NewDispatcher(client *http.Client, retry plotor.RetryPolicy) *Dispatcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Dispatcher{
+		client:   client,
+		retry:    retry,
+		webhooks: map[string]Webhook{},
+		logSize:  DEFAULT_LOG_SIZE,
+		stopC:    make(chan struct{}),
+		wg:       &sync.WaitGroup{},
+		mu:       &sync.Mutex{},
+	}
+}
+
+// AllowPrivateHosts makes Add accept webhooks pointing to loopback and private addresses, e.g. receivers running next to the server
+func (d *Dispatcher) AllowPrivateHosts() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.allowPrivate = true
+}
+
+// Add registers the webhook, a new ID is assigned when it has none
+func (d *Dispatcher) Add(w Webhook) (Webhook, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := w.validate(d.allowPrivate); err != nil {
+		return Webhook{}, err
+	}
+
+	if w.ID == "" {
+		w.ID = uuid.NewString()
+	}
+
+	d.webhooks[w.ID] = w
+
+	return w, nil
+}
+
+func (d *Dispatcher) Remove(webhookID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.webhooks[webhookID]; !ok {
+		return fmt.Errorf("webhook %s not found", webhookID)
+	}
+
+	delete(d.webhooks, webhookID)
+
+	return nil
+}
+
+// Webhooks returns registered webhooks sorted by ID
+func (d *Dispatcher) Webhooks() []Webhook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	webhooks := make([]Webhook, 0, len(d.webhooks))
+	for _, w := range d.webhooks {
+		webhooks = append(webhooks, w)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+
+	return webhooks
+}
+
+// Deliveries returns finished deliveries from the oldest to the most recent one,
+// they can be narrowed down to a webhook and limited to the most recent ones, empty webhook ID and zero limit match all deliveries
+func (d *Dispatcher) Deliveries(webhookID string, limit int) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := []Delivery{}
+
+	for _, delivery := range d.log {
+		if webhookID == "" || delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[len(deliveries)-limit:]
+	}
+
+	return deliveries
+}
+
+// Run dispatches events from the channel until it's closed
+func (d *Dispatcher) Run(events <-chan plotor.Event) {
+	for e := range events {
+		d.Dispatch(e)
+	}
+}
+
+// Dispatch sends the event to every webhook it matches, it does not wait for the deliveries
+func (d *Dispatcher) Dispatch(e plotor.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, w := range d.webhooks {
+		if !w.match(e) {
+			continue
+		}
+
+		d.wg.Add(1)
+
+		go func(w Webhook) {
+			defer d.wg.Done()
+			d.record(d.deliver(w, e))
+		}(w)
+	}
+}
+
+// Wait blocks until all deliveries in progress are finished
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Close stops retrying deliveries in progress and waits for them, events dispatched afterwards are sent once
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	select {
+	case <-d.stopC:
+	default:
+		close(d.stopC)
+	}
+	d.mu.Unlock()
+
+	d.Wait()
+}
+
+// deliver posts the event to the webhook until it's accepted, the response is a client error or the attempts run out
+func (d *Dispatcher) deliver(w Webhook, e plotor.Event) Delivery {
+	delivery := Delivery{
+		ID:        uuid.NewString(),
+		WebhookID: w.ID,
+		Event:     e,
+		Time:      time.Now(),
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("error marshalling event: %v", err)
+		return delivery
+	}
+
+	for {
+		delivery.Attempts++
+
+		statusCode, err := d.post(w, delivery.ID, e.Type, body)
+		delivery.StatusCode = statusCode
+
+		if err == nil {
+			delivery.Delivered = true
+			delivery.Error = ""
+			return delivery
+		}
+
+		delivery.Error = err.Error()
+
+		if !retryable(statusCode) || delivery.Attempts >= d.retry.MaxAttempts {
+			return delivery
+		}
+
+		timer := time.NewTimer(d.retry.Backoff(delivery.Attempts))
+
+		select {
+		case <-d.stopC:
+			timer.Stop()
+			return delivery
+		case <-timer.C:
+		}
+	}
+}
+
+func (d *Dispatcher) post(w Webhook, deliveryID string, typ plotor.EventType, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SIGNATURE_HEADER, Sign(w.Secret, body))
+	req.Header.Set(EVENT_HEADER, string(typ))
+	req.Header.Set(DELIVERY_HEADER, deliveryID)
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+// record appends the delivery to the log dropping the oldest deliveries above the log size
+func (d *Dispatcher) record(delivery Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.log = append(d.log, delivery)
+
+	if over := len(d.log) - d.logSize; over > 0 {
+		d.log = append([]Delivery{}, d.log[over:]...)
+	}
+}
+
+// retryable returns true if a delivery that got given status code can succeed later,
+// requests that were not sent, server errors and rate limits are retried
+func retryable(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
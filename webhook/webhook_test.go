@@ -0,0 +1,250 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotor/plotor"
+	"github.com/H3Cki/Plotor/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+var fastRetry = plotor.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+// receiver is a webhook endpoint responding with given status codes one by one, the last one is repeated
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, string) {
+	t.Helper()
+
+	r := &receiver{statuses: statuses}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+
+		status := r.statuses[0]
+		if len(r.statuses) > 1 {
+			r.statuses = r.statuses[1:]
+		}
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return r, server.URL
+}
+
+// newDispatcher returns a dispatcher accepting the receivers, they listen on loopback
+func newDispatcher() *webhook.Dispatcher {
+	d := webhook.NewDispatcher(nil, fastRetry)
+	d.AllowPrivateHosts()
+	return d
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func TestWebhook_Validate(t *testing.T) {
+	assert.NoError(t, webhook.Webhook{URL: "https://example.com/hook", Secret: "s"}.Validate())
+	assert.Error(t, webhook.Webhook{URL: "example.com/hook", Secret: "s"}.Validate())
+	assert.Error(t, webhook.Webhook{URL: "ftp://example.com", Secret: "s"}.Validate())
+	assert.Error(t, webhook.Webhook{URL: "https://example.com/hook"}.Validate())
+
+	tests := []struct {
+		url     string
+		private bool
+	}{
+		{url: "http://127.0.0.1:8080", private: true},
+		{url: "http://localhost/hook", private: true},
+		{url: "http://[::1]/hook", private: true},
+		{url: "http://10.0.0.1", private: true},
+		{url: "http://169.254.169.254/latest", private: true},
+		{url: "http://0.0.0.0", private: true},
+		{url: "http://0.1.2.3", private: true},
+		{url: "http://100.64.0.1", private: true},
+		{url: "http://100.127.255.254", private: true},
+		{url: "http://198.18.0.1", private: true},
+		{url: "http://198.19.255.254", private: true},
+		{url: "http://192.0.2.1", private: true},
+		{url: "http://240.0.0.1", private: true},
+		{url: "http://255.255.255.255", private: true},
+		{url: "http://[::ffff:10.0.0.1]", private: true},
+		{url: "http://[::ffff:127.0.0.1]", private: true},
+		{url: "http://[64:ff9b::a00:1]", private: true},
+		{url: "http://[64:ff9b::7f00:1]", private: true},
+		{url: "http://[64:ff9b::6440:1]", private: true},
+		{url: "http://[fd00::1]", private: true},
+		{url: "http://[fe80::1]", private: true},
+		{url: "http://[2001:db8::1]", private: true},
+		{url: "http://8.8.8.8"},
+		{url: "http://100.128.0.1"},
+		{url: "http://198.20.0.1"},
+		{url: "http://[::ffff:8.8.8.8]"},
+		{url: "http://[64:ff9b::808:808]"},
+		{url: "http://[2606:4700:4700::1111]"},
+	}
+
+	for _, tt := range tests {
+		err := webhook.Webhook{URL: tt.url, Secret: "s"}.Validate()
+		if tt.private {
+			assert.ErrorIs(t, err, webhook.ErrPrivateHost, tt.url)
+		} else {
+			assert.NoError(t, err, tt.url)
+		}
+	}
+}
+
+func TestDispatcher_PrivateHosts(t *testing.T) {
+	r, url := newReceiver(t, http.StatusOK)
+
+	_, err := webhook.NewDispatcher(nil, fastRetry).Add(webhook.Webhook{URL: url, Secret: "secret"})
+	assert.ErrorIs(t, err, webhook.ErrPrivateHost)
+
+	// hostnames are only resolved when connecting, the client refuses non-public addresses
+	d := webhook.NewDispatcher(webhook.NewHTTPClient(time.Second), plotor.RetryPolicy{})
+	d.AllowPrivateHosts()
+
+	_, err = d.Add(webhook.Webhook{URL: url, Secret: "secret"})
+	assert.NoError(t, err)
+
+	d.Dispatch(plotor.Event{Type: plotor.EventFilled})
+	d.Wait()
+
+	assert.Equal(t, 0, r.count())
+
+	deliveries := d.Deliveries("", 0)
+	assert.Len(t, deliveries, 1)
+	assert.False(t, deliveries[0].Delivered)
+	assert.Contains(t, deliveries[0].Error, webhook.ErrPrivateHost.Error())
+}
+
+func TestDispatcher_Signed(t *testing.T) {
+	r, url := newReceiver(t, http.StatusOK)
+
+	d := newDispatcher()
+	w, err := d.Add(webhook.Webhook{URL: url, Secret: "secret"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, w.ID)
+
+	e := plotor.Event{Type: plotor.EventFilled, PlotOrderID: "po", Status: plotor.StatusFilled}
+	d.Dispatch(e)
+	d.Wait()
+
+	assert.Equal(t, 1, r.count())
+
+	req, body := r.requests[0], r.bodies[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "FILLED", req.Header.Get(webhook.EVENT_HEADER))
+	assert.True(t, webhook.Verify("secret", body, req.Header.Get(webhook.SIGNATURE_HEADER)))
+	assert.False(t, webhook.Verify("other", body, req.Header.Get(webhook.SIGNATURE_HEADER)))
+
+	got := plotor.Event{}
+	assert.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, e, got)
+
+	deliveries := d.Deliveries("", 0)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, req.Header.Get(webhook.DELIVERY_HEADER), deliveries[0].ID)
+	assert.Equal(t, w.ID, deliveries[0].WebhookID)
+	assert.True(t, deliveries[0].Delivered)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+}
+
+func TestDispatcher_Filter(t *testing.T) {
+	r, url := newReceiver(t, http.StatusOK)
+
+	d := newDispatcher()
+	_, err := d.Add(webhook.Webhook{URL: url, Secret: "secret", Events: []plotor.EventType{plotor.EventFilled, plotor.EventError}})
+	assert.NoError(t, err)
+
+	for _, typ := range []plotor.EventType{plotor.EventCreated, plotor.EventRepriced, plotor.EventError, plotor.EventFilled} {
+		d.Dispatch(plotor.Event{Type: typ})
+	}
+	d.Wait()
+
+	assert.Equal(t, 2, r.count())
+	assert.Len(t, d.Deliveries("", 0), 2)
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		attempts   int
+		delivered  bool
+		statusCode int
+	}{
+		{"recovers", []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusNoContent}, 3, true, http.StatusNoContent},
+		{"runs out of attempts", []int{http.StatusBadGateway}, 3, false, http.StatusBadGateway},
+		{"client error", []int{http.StatusBadRequest}, 1, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, url := newReceiver(t, tt.statuses...)
+
+			d := newDispatcher()
+			w, err := d.Add(webhook.Webhook{URL: url, Secret: "secret"})
+			assert.NoError(t, err)
+
+			d.Dispatch(plotor.Event{Type: plotor.EventError})
+			d.Wait()
+
+			assert.Equal(t, tt.attempts, r.count())
+
+			deliveries := d.Deliveries(w.ID, 0)
+			assert.Len(t, deliveries, 1)
+			assert.Equal(t, tt.attempts, deliveries[0].Attempts)
+			assert.Equal(t, tt.delivered, deliveries[0].Delivered)
+			assert.Equal(t, tt.statusCode, deliveries[0].StatusCode)
+			assert.Equal(t, tt.delivered, deliveries[0].Error == "")
+		})
+	}
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	r, url := newReceiver(t, http.StatusOK)
+
+	d := newDispatcher()
+	first, err := d.Add(webhook.Webhook{URL: url, Secret: "a"})
+	assert.NoError(t, err)
+	second, err := d.Add(webhook.Webhook{URL: url, Secret: "b"})
+	assert.NoError(t, err)
+
+	events := make(chan plotor.Event, 3)
+	for i := 0; i < 3; i++ {
+		events <- plotor.Event{Type: plotor.EventRepriced}
+	}
+	close(events)
+
+	d.Run(events)
+	d.Wait()
+
+	assert.Equal(t, 6, r.count())
+	assert.Len(t, d.Deliveries(first.ID, 0), 3)
+	assert.Len(t, d.Deliveries(second.ID, 2), 2)
+
+	assert.NoError(t, d.Remove(first.ID))
+	assert.Error(t, d.Remove(first.ID))
+	assert.Equal(t, []webhook.Webhook{second}, d.Webhooks())
+}